@@ -0,0 +1,102 @@
+package audit_log
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// redacted replaces the values of redacted arguments
+const redacted = "[redacted]"
+
+// FileAuditLog appends audit entries to a JSON Lines file, one entry per line
+type FileAuditLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	redact []string
+}
+
+// NewFileAuditLog opens the log at path for appending, creating it and its
+// directory if needed. The values of the arguments named by redact are left
+// out, see domain.AuditSettings.
+func NewFileAuditLog(path string, redact []string) (*FileAuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileAuditLog{file: file, redact: redact}, nil
+}
+
+// Record writes the entry as one line. Entries recorded concurrently never interleave.
+func (l *FileAuditLog) Record(entry domain.AuditEntry) error {
+	if len(entry.Arguments) > 0 && len(l.redact) > 0 {
+		entry.Arguments = redactArguments(entry.Tool, entry.Arguments, l.redact)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file
+func (l *FileAuditLog) Close() error {
+	return l.file.Close()
+}
+
+// redactArguments replaces the values of the arguments the rules name for
+// the tool, at any depth. Arguments that are not JSON are left out entirely.
+func redactArguments(tool string, arguments json.RawMessage, rules []string) json.RawMessage {
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		if ruleTool, name, scoped := strings.Cut(rule, "."); !scoped {
+			names[rule] = true
+		} else if ruleTool == tool {
+			names[name] = true
+		}
+	}
+	if len(names) == 0 {
+		return arguments
+	}
+
+	var value any
+	if err := json.Unmarshal(arguments, &value); err != nil {
+		return json.RawMessage(`"` + redacted + `"`)
+	}
+	data, err := json.Marshal(redactValue(value, names))
+	if err != nil {
+		return json.RawMessage(`"` + redacted + `"`)
+	}
+	return data
+}
+
+func redactValue(value any, names map[string]bool) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if names[key] {
+				value[key] = redacted
+			} else {
+				value[key] = redactValue(item, names)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item, names)
+		}
+	}
+	return value
+}
@@ -0,0 +1,63 @@
+package audit_log
+
+import (
+	"bufio"
+	"encoding/json"
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileAuditLog_AppendsLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	started := time.Date(2026, 10, 16, 18, 25, 1, 0, time.UTC)
+
+	// Every run appends to the entries of the runs before it
+	for _, session := range []string{"first", "second"} {
+		auditLog, err := NewFileAuditLog(path, []string{"email", "crm__register_customer.notes"})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		entry := domain.NewToolCallEntry(session, "crm", "crm__register_customer",
+			[]byte(`{"name":"Ada","email":"ada@example.com","notes":"VIP","contacts":[{"email":"bob@example.com"}]}`), started)
+		if err := auditLog.Record(entry); err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		auditLog.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer file.Close()
+	var entries []domain.AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry domain.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("expected a JSON entry per line but got %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 2 || entries[0].Session != "first" || entries[1].Session != "second" {
+		t.Fatalf("expected the entries of both runs in order but got %+v", entries)
+	}
+	expected := `{"contacts":[{"email":"[redacted]"}],"email":"[redacted]","name":"Ada","notes":"[redacted]"}`
+	if string(entries[0].Arguments) != expected {
+		t.Errorf("expected arguments %s but got %s", expected, entries[0].Arguments)
+	}
+	if entries[0].Server != "crm" || !entries[0].Time.Equal(started) {
+		t.Errorf("expected the server and time of the call but got %+v", entries[0])
+	}
+}
+
+func TestRedactArguments_ScopedToTool(t *testing.T) {
+	arguments := json.RawMessage(`{"notes":"VIP"}`)
+	if redacted := redactArguments("billing__charge", arguments, []string{"crm__register_customer.notes"}); string(redacted) != string(arguments) {
+		t.Errorf("expected the arguments of other tools to be kept but got %s", redacted)
+	}
+}
@@ -0,0 +1,75 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"mcp_client/core/ports"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// Client sends conversations to Claude through the Anthropic API
+type Client struct {
+	client anthropic.Client
+}
+
+// NewClient creates a new Client instance
+func NewClient(apiKey string, opts ...option.RequestOption) *Client {
+	return &Client{
+		client: anthropic.NewClient(
+			append([]option.RequestOption{option.WithAPIKey(apiKey)}, opts...)...,
+		),
+	}
+}
+
+// CreateMessage streams Claude's response, passing text deltas on as they
+// arrive, and returns the accumulated message including complete tool_use blocks.
+func (c *Client) CreateMessage(ctx context.Context, params anthropic.MessageNewParams, onTextDelta func(text string)) (*ports.ModelResponse, error) {
+	var httpResponse *http.Response
+	stream := c.client.Messages.NewStreaming(ctx, params, option.WithResponseInto(&httpResponse))
+	defer stream.Close()
+
+	message := anthropic.Message{}
+	for stream.Next() {
+		event := stream.Current()
+		if err := message.Accumulate(event); err != nil {
+			return nil, fmt.Errorf("failed to accumulate stream event: %w", err)
+		}
+
+		if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok {
+			if text, ok := delta.Delta.AsAny().(anthropic.TextDelta); ok {
+				onTextDelta(text.Text)
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	response := &ports.ModelResponse{Message: &message}
+	if httpResponse != nil {
+		response.RequestID = httpResponse.Header.Get("request-id")
+	}
+	return response, nil
+}
+
+// CountTokens asks the API how many input tokens the request would use
+func (c *Client) CountTokens(ctx context.Context, params anthropic.MessageNewParams) (int64, error) {
+	countParams := anthropic.MessageCountTokensParams{
+		Messages:   params.Messages,
+		Model:      params.Model,
+		System:     anthropic.MessageCountTokensParamsSystemUnion{OfTextBlockArray: params.System},
+		ToolChoice: params.ToolChoice,
+	}
+	for _, tool := range params.Tools {
+		countParams.Tools = append(countParams.Tools, anthropic.MessageCountTokensToolUnionParam{OfTool: tool.OfTool})
+	}
+
+	count, err := c.client.Messages.CountTokens(ctx, countParams)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return count.InputTokens, nil
+}
@@ -0,0 +1,69 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// streamEvents is a streamed response with a text block followed by a tool_use block whose input arrives in pieces
+var streamEvents = []string{
+	`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-test","content":[],"usage":{"input_tokens":10,"output_tokens":1}}}`,
+	`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+	`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Looking "}}`,
+	`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"it up."}}`,
+	`{"type":"content_block_stop","index":0}`,
+	`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"crm__lookup","input":{}}}`,
+	`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"id\": "}}`,
+	`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"42\"}"}}`,
+	`{"type":"content_block_stop","index":1}`,
+	`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+	`{"type":"message_stop"}`,
+}
+
+func TestClient_CreateMessageStreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("request-id", "req_1")
+		for _, event := range streamEvents {
+			eventType := strings.SplitN(strings.TrimPrefix(event, `{"type":"`), `"`, 2)[0]
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, event)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+	var deltas []string
+	message, err := client.CreateMessage(context.Background(), anthropic.MessageNewParams{
+		Model:     "claude-test",
+		MaxTokens: 1024,
+	}, func(text string) {
+		deltas = append(deltas, text)
+	})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	if strings.Join(deltas, "|") != "Looking |it up." {
+		t.Errorf("expected the text deltas in order but got: %q", deltas)
+	}
+	if len(message.Content) != 2 || message.Content[0].Text != "Looking it up." {
+		t.Fatalf("expected the accumulated text block but got: %+v", message.Content)
+	}
+	toolUse := message.Content[1]
+	if toolUse.Type != "tool_use" || toolUse.ID != "toolu_1" || string(toolUse.Input) != `{"id": "42"}` {
+		t.Errorf("expected the complete tool_use block but got: %s %s %s", toolUse.Type, toolUse.ID, toolUse.Input)
+	}
+	if message.StopReason != anthropic.StopReasonToolUse {
+		t.Errorf("expected stop reason tool_use but got %q", message.StopReason)
+	}
+	if message.RequestID != "req_1" {
+		t.Errorf("expected the request ID from the response headers but got %q", message.RequestID)
+	}
+}
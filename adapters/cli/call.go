@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp_client/adapters/mcp_servers"
+	"mcp_client/core/domain"
+	"mcp_client/core/usecases/conversation"
+	"os"
+	"time"
+)
+
+func runCall(args []string) int {
+	flags := newFlagSet("call", "<tool> [json-arguments]", "Invoke a tool by its namespaced name, e.g. crm__register_customer, and print its result. A failed call is printed to stderr and exits with status 1.")
+	servers := addServerFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return exitUsage
+	}
+	cfg, err := loadConfig(flags, servers, nil)
+	if err != nil {
+		return fail(err)
+	}
+
+	name, arguments := flags.Arg(0), "{}"
+	if flags.NArg() == 2 {
+		arguments = flags.Arg(1)
+	}
+	if !json.Valid([]byte(arguments)) {
+		return fail(fmt.Errorf("arguments are not valid JSON: %s", arguments))
+	}
+
+	registry, err := connect(cfg, unattended{}, mcp_servers.NewRoots(cfg.Roots))
+	if err != nil {
+		return fail(err)
+	}
+	defer registry.Close()
+
+	auditLog, closeAuditLog, err := openAuditLog(cfg)
+	if err != nil {
+		return fail(err)
+	}
+	defer closeAuditLog()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Chat.ToolTimeout)
+	defer cancel()
+
+	// The call is outside any conversation and made on the user's own behalf
+	entry := domain.NewToolCallEntry("", registry.ToolServer(name), name, []byte(arguments), time.Now())
+	entry.Operator = userName()
+	entry.Cleared, entry.ApprovedBy = domain.ClearedByUser, entry.Operator
+	result := registry.CallTool(ctx, name, []byte(arguments))
+	if auditLog != nil {
+		conversation.CompleteToolCallEntry(&entry, result, time.Now())
+		if err := auditLog.Record(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record the call in the audit log: %v\n", err)
+		}
+	}
+	out := os.Stdout
+	if result.IsError.Value {
+		out = os.Stderr
+	}
+	for _, content := range result.Content {
+		if content.OfText != nil {
+			fmt.Fprintln(out, content.OfText.Text)
+		} else if content.OfImage != nil {
+			fmt.Fprintln(out, describeImage(content.OfImage))
+		}
+	}
+	if result.IsError.Value {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"mcp_client/adapters/mcp_servers"
+	"mcp_client/core/usecases/conversation"
+	"os"
+	"strings"
+)
+
+// greeting opens every interactive chat
+const greeting = "How can you help me? Write a concise response."
+
+func runChat(args []string) int {
+	flags := newFlagSet("chat", "", "Talk to Claude interactively. Type /prompts to list the prompts of the servers, /roots to manage the directories they may work in, /watch <resource-uri> to follow changes of a resource and exit to quit. Ctrl-C cancels the running tool calls.")
+	servers := addServerFlags(flags)
+	model := addModelFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	cfg, err := loadConfig(flags, servers, model)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Questions of the servers are read from stdin like the chat messages
+	user := newTerminal(os.Stdin, os.Stdout)
+	roots := mcp_servers.NewRoots(cfg.Roots)
+	registry, err := connect(cfg, user, roots)
+	if err != nil {
+		return fail(err)
+	}
+	defer registry.Close()
+
+	tools := registry.Tools()
+	fmt.Printf("%d tools available\n", len(tools))
+	for i, tool := range tools {
+		fmt.Printf("  %d. %s - %s\n", i+1, tool.Name, tool.Tool.Description)
+	}
+
+	resources := registry.Resources()
+	fmt.Printf("%d resources available\n", len(resources)+len(registry.ResourceTemplates()))
+	for i, resource := range resources {
+		fmt.Printf("  %d. %s - %s\n", i+1, resource.Resource.URI, resource.Name)
+	}
+	for i, template := range registry.ResourceTemplates() {
+		fmt.Printf("  %d. %s - %s\n", len(resources)+i+1, template.Template.URITemplate.Raw(), template.Name)
+	}
+
+	if prompts := registry.Prompts(); len(prompts) > 0 {
+		fmt.Printf("%d prompts available, type /prompts to list them\n", len(prompts))
+	}
+
+	auditLog, closeAuditLog, err := openAuditLog(cfg)
+	if err != nil {
+		return fail(err)
+	}
+	defer closeAuditLog()
+
+	chat, err := newConversation(cfg, registry, user, auditLog, &printer{out: os.Stdout, info: os.Stdout, color: true}, model.resume)
+	if err != nil {
+		return fail(err)
+	}
+
+	if cfg.Chat.DryRun {
+		fmt.Println("Dry run: tools that change data are simulated")
+	}
+	registry.OnProgress((&progressBar{out: os.Stdout}).show)
+	registry.OnResourceUpdated(reportResourceUpdates(cfg.ResourceUpdates, chat, cfg.Chat.ToolResultLimit))
+	registry.OnListChanged(reportListChange)
+
+	input := greeting
+	if model.resume != "" {
+		// A resumed conversation picks up where it stopped instead of greeting again
+		session := chat.Session()
+		fmt.Printf("Resuming session %s: %s (%d messages)\n", session.ID, session.Title, len(chat.Messages()))
+		if input, err = user.readInput(); err != nil || input == "exit" {
+			return exitOK
+		}
+	} else {
+		fmt.Printf("Session %s\n", chat.Session().ID)
+	}
+
+	commands := &slashCommands{registry: registry, chat: chat, roots: roots, timeout: cfg.Chat.ToolTimeout}
+	for {
+		err = interruptible(registry, func(ctx context.Context) error {
+			if strings.HasPrefix(input, "/") {
+				return commands.run(ctx, input)
+			}
+			_, err := chat.Send(ctx, conversation.SendInput{Text: input})
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+
+		input, err = user.readInput()
+		if err != nil || input == "exit" {
+			return exitOK
+		}
+	}
+}
+
+// fail reports an error that ends the command
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return exitError
+}
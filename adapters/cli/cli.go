@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes returned by Run
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `Usage: mcp_client <command> [flags] [arguments]
+
+Commands:
+  chat      Talk to Claude interactively using the tools of the configured servers (default)
+  run       Answer a single prompt, given as arguments or on stdin, and exit
+  tools     List the tools, resources and prompts of the configured servers
+  call      Invoke a single tool with JSON arguments and print its result
+  sessions  List the saved conversations, or export one to Markdown
+
+Run "mcp_client <command> -h" to see the flags of a command.
+`
+
+// Run executes the command line and returns the process exit code
+func Run(args []string) int {
+	command := "chat"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "chat":
+		return runChat(args)
+	case "run":
+		return runOnce(args)
+	case "tools":
+		return runTools(args)
+	case "call":
+		return runCall(args)
+	case "sessions":
+		return runSessions(args)
+	case "help":
+		printUsage(os.Stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usage)
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mcp_client/core/domain"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// writeMarkdown renders a saved conversation, including its tool calls and results, as Markdown
+func writeMarkdown(w io.Writer, session domain.Session, messages []anthropic.MessageParam) error {
+	out := bufio.NewWriter(w)
+
+	title := session.Title
+	if title == "" {
+		title = "Session " + session.ID
+	}
+	fmt.Fprintf(out, "# %s\n\n", title)
+	fmt.Fprintf(out, "- Session: `%s`\n", session.ID)
+	fmt.Fprintf(out, "- Model: %s\n", session.Model)
+	fmt.Fprintf(out, "- Started: %s\n", session.CreatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(out, "- Updated: %s\n", session.UpdatedAt.Local().Format("2006-01-02 15:04"))
+
+	for _, message := range messages {
+		// Tool results travel in user messages but are not written by the user
+		if message.Role == anthropic.MessageParamRoleUser && !hasToolResultsOnly(message) {
+			fmt.Fprint(out, "\n## User\n")
+		} else if message.Role == anthropic.MessageParamRoleAssistant {
+			fmt.Fprint(out, "\n## Assistant\n")
+		}
+
+		for _, content := range message.Content {
+			switch {
+			case content.OfText != nil:
+				fmt.Fprintf(out, "\n%s\n", content.OfText.Text)
+			case content.OfToolUse != nil:
+				input, err := json.MarshalIndent(content.OfToolUse.Input, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "\n**Tool call** `%s`\n\n", content.OfToolUse.Name)
+				writeCodeBlock(out, "json", string(input))
+			case content.OfToolResult != nil:
+				writeToolResult(out, content.OfToolResult)
+			}
+		}
+	}
+	return out.Flush()
+}
+
+func writeToolResult(out io.Writer, result *anthropic.ToolResultBlockParam) {
+	heading := "Tool result"
+	if result.IsError.Value {
+		heading = "Tool error"
+	}
+	fmt.Fprintf(out, "\n**%s**\n\n", heading)
+
+	var text []string
+	for _, content := range result.Content {
+		if content.OfText != nil {
+			text = append(text, content.OfText.Text)
+		} else if content.OfImage != nil {
+			text = append(text, describeImage(content.OfImage))
+		}
+	}
+	writeCodeBlock(out, "", strings.Join(text, "\n"))
+}
+
+// writeCodeBlock fences text with enough backticks that fences inside it cannot end the block
+func writeCodeBlock(out io.Writer, language, text string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(out, "%s%s\n%s\n%s\n", fence, language, strings.TrimRight(text, "\n"), fence)
+}
+
+func hasToolResultsOnly(message anthropic.MessageParam) bool {
+	for _, content := range message.Content {
+		if content.OfToolResult == nil {
+			return false
+		}
+	}
+	return len(message.Content) > 0
+}
+
+// describeImage stands in for an image that is not worth embedding in text output
+func describeImage(image *anthropic.ImageBlockParam) string {
+	if source := image.Source.OfBase64; source != nil {
+		return fmt.Sprintf("[%s image, %d bytes]", source.MediaType, base64.StdEncoding.DecodedLen(len(source.Data)))
+	}
+	return "[image]"
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"mcp_client/adapters/config"
+	"mcp_client/core/domain"
+	"strings"
+	"time"
+)
+
+// serverFlags are the flags of every command that talks to MCP servers
+type serverFlags struct {
+	configFile     string
+	servers        serverList
+	connectTimeout time.Duration
+	toolTimeout    time.Duration
+}
+
+// modelFlags are the flags of commands that talk to Claude
+type modelFlags struct {
+	model            string
+	maxTokens        int64
+	modelTimeout     time.Duration
+	systemPromptFile string
+	resume           string
+	dryRun           bool
+}
+
+// serverList collects repeated -server flags of the form [name=]url
+type serverList []domain.ServerConfig
+
+func (l *serverList) String() string {
+	urls := make([]string, len(*l))
+	for i, server := range *l {
+		urls[i] = server.Name + "=" + server.URL
+	}
+	return strings.Join(urls, ",")
+}
+
+func (l *serverList) Set(value string) error {
+	name, url, named := strings.Cut(value, "=")
+	if !named {
+		name, url = "server", value
+	}
+	server := domain.NewServerConfig(name, url)
+	if !server.IsValid() {
+		return fmt.Errorf("expected [name=]url but got %q", value)
+	}
+	for _, existing := range *l {
+		if existing.Name == name {
+			return fmt.Errorf("duplicate server name %q, name the servers as name=url", name)
+		}
+	}
+	*l = append(*l, *server)
+	return nil
+}
+
+func newFlagSet(name, arguments, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: mcp_client %s [flags] %s\n\n%s\n\nFlags:\n", name, arguments, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+var configFlagUsage = "config file (default $" + config.EnvConfigFile + " or " + strings.Join(config.DefaultConfigFiles, ", ") + ")"
+
+// addConfigFlag adds only the config file flag, for commands that do not talk to servers
+func addConfigFlag(flags *flag.FlagSet) *string {
+	return flags.String("config", "", configFlagUsage)
+}
+
+// Flag defaults are only shown in the help; a flag overrides the configuration only when it is set.
+
+func addServerFlags(flags *flag.FlagSet) *serverFlags {
+	defaults := config.DefaultClientConfig()
+	f := &serverFlags{}
+	flags.StringVar(&f.configFile, "config", "", configFlagUsage)
+	flags.Var(&f.servers, "server", "MCP server as [name=]url, replacing the configured servers (repeatable)")
+	flags.DurationVar(&f.connectTimeout, "connect-timeout", defaults.ConnectTimeout, "time allowed for connecting to the servers")
+	flags.DurationVar(&f.toolTimeout, "tool-timeout", defaults.Chat.ToolTimeout, "time allowed for a single tool call")
+	return f
+}
+
+func addModelFlags(flags *flag.FlagSet) *modelFlags {
+	defaults := config.DefaultClientConfig()
+	f := &modelFlags{}
+	flags.StringVar(&f.model, "model", defaults.Chat.Model, "Claude model to use")
+	flags.Int64Var(&f.maxTokens, "max-tokens", defaults.Chat.MaxTokens, "maximum number of tokens per response")
+	flags.DurationVar(&f.modelTimeout, "model-timeout", defaults.Chat.ModelTimeout, "time allowed for a single model response")
+	flags.StringVar(&f.systemPromptFile, "system-prompt-file", "", "file containing the system prompt")
+	flags.StringVar(&f.resume, "resume", "", "continue the saved session with this ID (see the sessions command)")
+	flags.BoolVar(&f.dryRun, "dry-run", false, "simulate the calls of tools that change data instead of making them")
+	return f
+}
+
+// loadConfig loads the layered configuration, puts the flags that were set on
+// top and validates the result. model is nil for commands without model flags.
+func loadConfig(flags *flag.FlagSet, servers *serverFlags, model *modelFlags) (domain.ClientConfig, error) {
+	cfg, err := config.Load(servers.configFile)
+	if err != nil {
+		return cfg, err
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if set["server"] {
+		cfg.Servers = servers.servers
+	}
+	if set["connect-timeout"] {
+		cfg.ConnectTimeout = servers.connectTimeout
+	}
+	if set["tool-timeout"] {
+		cfg.Chat.ToolTimeout = servers.toolTimeout
+	}
+
+	if model != nil {
+		if set["model"] {
+			cfg.Chat.Model = model.model
+		}
+		if set["max-tokens"] {
+			cfg.Chat.MaxTokens = model.maxTokens
+		}
+		if set["model-timeout"] {
+			cfg.Chat.ModelTimeout = model.modelTimeout
+		}
+		if set["dry-run"] {
+			cfg.Chat.DryRun = model.dryRun
+		}
+		if set["system-prompt-file"] {
+			systemPrompt, err := config.ReadSystemPrompt(model.systemPromptFile)
+			if err != nil {
+				return cfg, err
+			}
+			cfg.Chat.SystemPrompt = systemPrompt
+		}
+	}
+
+	// Servers sample with the chat model unless they are given their own
+	if cfg.Sampling.Model == "" {
+		cfg.Sampling.Model = cfg.Chat.Model
+	}
+	cfg.Sampling.ModelTimeout = cfg.Chat.ModelTimeout
+
+	return cfg, config.Validate(cfg)
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+)
+
+// printer shows a conversation on a terminal or in a pipe
+type printer struct {
+	out   io.Writer
+	info  io.Writer
+	color bool
+}
+
+func (p *printer) OnTextDelta(text string) {
+	if p.color {
+		fmt.Fprintf(p.out, "\033[94m%s\033[0m", text)
+		return
+	}
+	fmt.Fprint(p.out, text)
+}
+
+// OnText ends the line of a text block that was printed delta by delta
+func (p *printer) OnText(text string) {
+	fmt.Fprintln(p.out)
+}
+
+func (p *printer) OnToolUse(name string, input []byte) {
+	if p.color {
+		fmt.Fprintf(p.info, "\033[33mTool use:%s - %s\033[0m\n", name, string(input))
+		return
+	}
+	fmt.Fprintf(p.info, "Tool use:%s - %s\n", name, string(input))
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mcp_client/adapters/mcp_servers"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+)
+
+// progressWidth is the number of characters of a progress bar
+const progressWidth = 30
+
+// progressBar draws the progress of running tool calls on a single terminal line
+type progressBar struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (b *progressBar) show(progress mcp_servers.ToolProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Every update replaces the line; the end of the call clears it
+	fmt.Fprint(b.out, "\r\033[K")
+	if progress.Done {
+		return
+	}
+	if progress.Total > 0 {
+		fraction := min(max(progress.Progress/progress.Total, 0), 1)
+		filled := int(fraction * progressWidth)
+		fmt.Fprintf(b.out, "%s [%s%s] %3.0f%%", progress.Tool,
+			strings.Repeat("#", filled), strings.Repeat("-", progressWidth-filled), fraction*100)
+	} else {
+		fmt.Fprintf(b.out, "%s %g", progress.Tool, progress.Progress)
+	}
+	if progress.Message != "" {
+		fmt.Fprintf(b.out, " %s", progress.Message)
+	}
+}
+
+// interruptible runs one chat turn. Ctrl-C cancels the tool calls in flight,
+// which Claude learns about from their results, or the turn itself when no
+// tool is running. Either way the chat goes on.
+func interruptible(registry *mcp_servers.Registry, turn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	done := make(chan error, 1)
+	go func() { done <- turn(ctx) }()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-interrupts:
+			if calls := registry.CancelCalls(); calls > 0 {
+				fmt.Printf("\nCancelled %d tool calls\n", calls)
+			} else {
+				fmt.Println("\nCancelled")
+				cancel()
+			}
+		}
+	}
+}
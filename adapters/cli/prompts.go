@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"mcp_client/adapters/mcp_servers"
+	"mcp_client/core/usecases/conversation"
+	"strings"
+	"time"
+)
+
+// slashCommands handles chat input starting with a slash: /prompts lists the
+// prompts of the servers, /roots manages the directories they may work in,
+// /watch and /unwatch follow changes of resources and /<prompt> key=value ...
+// adds a prompt to the conversation
+type slashCommands struct {
+	registry *mcp_servers.Registry
+	chat     *conversation.ConversationUsecase
+	roots    *mcp_servers.Roots
+	timeout  time.Duration
+}
+
+func (c *slashCommands) run(ctx context.Context, input string) error {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+	switch name {
+	case "prompts":
+		c.printPrompts()
+		return nil
+	case "roots":
+		return c.manageRoots(ctx, rest)
+	case "watch":
+		return c.watch(ctx, strings.TrimSpace(rest))
+	case "unwatch":
+		return c.unwatch(ctx, strings.TrimSpace(rest))
+	}
+
+	arguments, err := parseArguments(rest)
+	if err != nil {
+		return err
+	}
+	promptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	messages, err := c.registry.GetPrompt(promptCtx, name, arguments)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("%w\nType /prompts to list the prompts", err)
+	}
+
+	fmt.Printf("Prompt %s added %d messages\n", name, len(messages))
+	_, err = c.chat.SendPrompt(ctx, messages)
+	return err
+}
+
+func (c *slashCommands) printPrompts() {
+	prompts := c.registry.Prompts()
+	fmt.Printf("%d prompts available\n", len(prompts))
+	for _, prompt := range prompts {
+		fmt.Printf("  /%s\n", prompt.Usage())
+		if prompt.Prompt.Description != "" {
+			fmt.Printf("      %s\n", prompt.Prompt.Description)
+		}
+		for _, argument := range prompt.Prompt.Arguments {
+			if argument.Description != "" {
+				fmt.Printf("      %s: %s\n", argument.Name, argument.Description)
+			}
+		}
+	}
+}
+
+// parseArguments reads key=value pairs separated by spaces. Values containing
+// spaces are quoted: name="Ada Lovelace".
+func parseArguments(text string) (map[string]string, error) {
+	fields, err := splitFields(text)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := make(map[string]string, len(fields))
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value but got %q", field)
+		}
+		arguments[key] = value
+	}
+	return arguments, nil
+}
+
+// splitFields splits text at spaces outside single or double quotes and removes the quotes
+func splitFields(text string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField := false
+	var quote rune
+	for _, r := range text {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			field.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// rootsUsage describes the /roots command
+const rootsUsage = "usage: /roots [add <dir> | remove <dir>]"
+
+// manageRoots lists the roots, or adds or removes one and tells the servers
+func (c *slashCommands) manageRoots(ctx context.Context, arguments string) error {
+	fields, err := splitFields(arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(fields) == 0 {
+		paths := c.roots.Paths()
+		fmt.Printf("%d roots\n", len(paths))
+		for _, path := range paths {
+			fmt.Printf("  %s\n", path)
+		}
+		return nil
+	}
+	if len(fields) != 2 {
+		return errors.New(rootsUsage)
+	}
+
+	var path, done string
+	switch fields[0] {
+	case "add":
+		path, err = c.roots.Add(fields[1])
+		done = "added"
+	case "remove":
+		path, err = c.roots.Remove(fields[1])
+		done = "removed"
+	default:
+		return errors.New(rootsUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	notifyCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	c.registry.NotifyRootsChanged(notifyCtx)
+	fmt.Printf("Root %s %s\n", path, done)
+	return nil
+}
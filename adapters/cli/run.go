@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mcp_client/adapters/mcp_servers"
+	"mcp_client/core/usecases/conversation"
+	"os"
+	"strings"
+)
+
+func runOnce(args []string) int {
+	flags := newFlagSet("run", "[prompt]", "Answer a single prompt and exit. Without a prompt, or with -, the prompt is read from stdin.")
+	servers := addServerFlags(flags)
+	model := addModelFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	cfg, err := loadConfig(flags, servers, model)
+	if err != nil {
+		return fail(err)
+	}
+
+	prompt := strings.Join(flags.Args(), " ")
+	if prompt == "" || prompt == "-" {
+		stdin, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fail(fmt.Errorf("failed to read prompt: %w", err))
+		}
+		prompt = string(stdin)
+	}
+
+	registry, err := connect(cfg, unattended{}, mcp_servers.NewRoots(cfg.Roots))
+	if err != nil {
+		return fail(err)
+	}
+	defer registry.Close()
+
+	// Only the answer goes to stdout so it can be piped
+	auditLog, closeAuditLog, err := openAuditLog(cfg)
+	if err != nil {
+		return fail(err)
+	}
+	defer closeAuditLog()
+
+	chat, err := newConversation(cfg, registry, unattended{}, auditLog, &printer{out: os.Stdout, info: os.Stderr}, model.resume)
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "Session %s\n", chat.Session().ID)
+	if cfg.Chat.DryRun {
+		fmt.Fprintln(os.Stderr, "Dry run: tools that change data are simulated")
+	}
+	if _, err := chat.Send(context.Background(), conversation.SendInput{Text: prompt}); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
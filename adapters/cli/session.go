@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"mcp_client/adapters/audit_log"
+	"mcp_client/adapters/claude"
+	"mcp_client/adapters/config"
+	"mcp_client/adapters/mcp_servers"
+	"mcp_client/adapters/session_store"
+	"mcp_client/core/domain"
+	"mcp_client/core/ports"
+	"mcp_client/core/usecases/conversation"
+	"mcp_client/core/usecases/sampling"
+)
+
+// user answers the questions that come up while a command runs
+type user interface {
+	ports.ToolApproverPort
+	ports.SamplingApproverPort
+	mcp_servers.Elicitor
+}
+
+// connect starts the configured MCP servers. When user is set servers may ask
+// for input, and request model completions if sampling is enabled and there is
+// an API key. They learn the directories they may work in when roots is set.
+func connect(cfg domain.ClientConfig, user user, roots *mcp_servers.Roots) (*mcp_servers.Registry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+
+	var options []mcp_servers.DialerOption
+	if roots != nil {
+		options = append(options, mcp_servers.WithRoots(roots))
+	}
+	if user != nil {
+		options = append(options, mcp_servers.WithElicitor(user))
+	}
+	if user != nil && cfg.Sampling.Enabled() && cfg.APIKey != "" {
+		sampler := sampling.NewSamplingUsecase(claude.NewClient(cfg.APIKey), user, cfg.Sampling)
+		options = append(options, mcp_servers.WithSampler(sampler))
+	}
+
+	registry := mcp_servers.NewRegistry(cfg.Servers, mcp_servers.NewDialer(options...).Connect)
+	if err := registry.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start MCP servers: %w", err)
+	}
+	return registry, nil
+}
+
+// openAuditLog opens the configured audit log. Without one nothing is audited
+// and the returned close func does nothing.
+func openAuditLog(cfg domain.ClientConfig) (ports.AuditLogPort, func(), error) {
+	if cfg.Audit.File == "" {
+		return nil, func() {}, nil
+	}
+	auditLog, err := audit_log.NewFileAuditLog(cfg.Audit.File, cfg.Audit.Redact)
+	if err != nil {
+		return nil, nil, err
+	}
+	return auditLog, func() { auditLog.Close() }, nil
+}
+
+// newConversation wires a conversation with Claude to the tools of the registry,
+// asking user about the calls that need approval and recording them in
+// auditLog. It continues the saved session resume, or starts a new one when
+// resume is empty.
+func newConversation(
+	cfg domain.ClientConfig,
+	registry *mcp_servers.Registry,
+	user user,
+	auditLog ports.AuditLogPort,
+	observer ports.ConversationObserverPort,
+	resume string,
+) (*conversation.ConversationUsecase, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("%s is not set", config.EnvAPIKey)
+	}
+
+	settings := cfg.Chat
+	settings.Operator = userName()
+	chat := conversation.NewConversationUsecase(
+		claude.NewClient(cfg.APIKey),
+		registry,
+		user,
+		observer,
+		session_store.NewFileSessionStore(cfg.SessionsDir),
+		auditLog,
+		settings,
+	)
+	if resume != "" {
+		if err := chat.Resume(resume); err != nil {
+			return nil, err
+		}
+	}
+	return chat, nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"mcp_client/adapters/config"
+	"mcp_client/adapters/session_store"
+	"os"
+	"text/tabwriter"
+)
+
+func runSessions(args []string) int {
+	if len(args) > 0 && args[0] == "export" {
+		return runExport(args[1:])
+	}
+
+	flags := newFlagSet("sessions", "", "List the saved conversations, most recent first. Use \"sessions export <id>\" to export one to Markdown.")
+	configFile := addConfigFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	store, err := openSessionStore(*configFile)
+	if err != nil {
+		return fail(err)
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		return fail(err)
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No saved sessions")
+		return exitOK
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tUPDATED\tMESSAGES\tTITLE")
+	for _, session := range sessions {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\n", session.ID, session.UpdatedAt.Local().Format("2006-01-02 15:04"), session.MessageCount, session.Title)
+	}
+	table.Flush()
+	return exitOK
+}
+
+func runExport(args []string) int {
+	flags := newFlagSet("sessions export", "<id>", "Export a saved conversation, including its tool calls and results, as Markdown.")
+	configFile := addConfigFlag(flags)
+	output := flags.String("o", "", "file to write the Markdown to (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	store, err := openSessionStore(*configFile)
+	if err != nil {
+		return fail(err)
+	}
+
+	session, messages, err := store.Load(flags.Arg(0))
+	if err != nil {
+		return fail(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fail(fmt.Errorf("failed to create export file: %w", err))
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeMarkdown(out, *session, messages); err != nil {
+		return fail(fmt.Errorf("failed to export session: %w", err))
+	}
+	return exitOK
+}
+
+// openSessionStore opens the sessions directory of the configuration
+func openSessionStore(configFile string) (*session_store.FileSessionStore, error) {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SessionsDir == "" {
+		return nil, fmt.Errorf("invalid configuration: sessions_dir: is required")
+	}
+	return session_store.NewFileSessionStore(cfg.SessionsDir), nil
+}
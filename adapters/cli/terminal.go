@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mcp_client/core/domain"
+	"os"
+	osuser "os/user"
+	"strings"
+	"sync"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// terminal reads the chat messages and asks the user the questions that come
+// up while the servers run: tool call and sampling approvals, and the forms of
+// servers. One goroutine reads stdin and hands each line to a single reader,
+// so prompts never interleave. Questions wait for each other, and a question
+// arriving while the chat prompt waits for a message takes the terminal over.
+type terminal struct {
+	out   io.Writer
+	lines <-chan string
+	// err is why reading stopped, set before lines is closed
+	err error
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// busy is set while a question or the chat prompt reads lines
+	busy bool
+	// questions counts the questions waiting for the terminal
+	questions int
+	// interrupt tells the chat prompt that a question is waiting
+	interrupt chan struct{}
+}
+
+// newTerminal starts reading the lines of in
+func newTerminal(in io.Reader, out io.Writer) *terminal {
+	lines := make(chan string)
+	t := &terminal{out: out, lines: lines, interrupt: make(chan struct{}, 1)}
+	t.cond = sync.NewCond(&t.mu)
+	go t.read(bufio.NewReader(in), lines)
+	return t
+}
+
+// read hands out the lines of stdin one at a time until it is closed
+func (t *terminal) read(reader *bufio.Reader, lines chan<- string) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			t.err = err
+			close(lines)
+			return
+		}
+		lines <- line
+	}
+}
+
+// acquire waits until no other question or the chat prompt reads the
+// terminal, interrupting the chat prompt, and returns the release function
+func (t *terminal) acquire() func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.questions++
+	select {
+	case t.interrupt <- struct{}{}:
+	default:
+	}
+	for t.busy {
+		t.cond.Wait()
+	}
+	t.questions--
+	t.busy = true
+	return t.release
+}
+
+func (t *terminal) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.busy = false
+	t.cond.Broadcast()
+}
+
+// ask prints prompt and reads a line. It returns io.EOF when stdin is closed,
+// and the error of ctx when it is done first, leaving the next line to the next reader.
+func (t *terminal) ask(ctx context.Context, prompt string) (string, error) {
+	fmt.Fprint(t.out, prompt)
+	select {
+	case line, ok := <-t.lines:
+		if !ok {
+			return "", t.err
+		}
+		return strings.TrimSpace(line), nil
+	case <-ctx.Done():
+		fmt.Fprintln(t.out)
+		return "", ctx.Err()
+	}
+}
+
+// readInput prompts until the user enters a non-empty chat message. Questions
+// come first: a question waiting for the terminal ends the prompt, which is
+// shown again once the question is answered. It returns io.EOF when stdin is closed.
+func (t *terminal) readInput() (string, error) {
+	for {
+		t.mu.Lock()
+		for t.busy || t.questions > 0 {
+			t.cond.Wait()
+		}
+		t.busy = true
+		// No question waits, so an interruption left over is stale
+		select {
+		case <-t.interrupt:
+		default:
+		}
+		t.mu.Unlock()
+
+		fmt.Fprint(t.out, "> ")
+		select {
+		case line, ok := <-t.lines:
+			t.release()
+			if !ok {
+				if !errors.Is(t.err, io.EOF) {
+					return "", t.err
+				}
+				fmt.Fprintln(t.out)
+				return "", io.EOF
+			}
+			if input := strings.TrimSpace(line); input != "" {
+				return input, nil
+			}
+		case <-t.interrupt:
+			fmt.Fprintln(t.out)
+			t.release()
+		}
+	}
+}
+
+// ApproveSampling shows the model request of a server and asks the user to allow it
+func (t *terminal) ApproveSampling(ctx context.Context, server string, params anthropic.MessageNewParams, remaining int64) (bool, error) {
+	defer t.acquire()()
+
+	fmt.Fprintf(t.out, "Server %s asks %s for up to %d tokens (%d left of its budget):\n", server, params.Model, params.MaxTokens, remaining)
+	for _, system := range params.System {
+		fmt.Fprintf(t.out, "  system: %s\n", system.Text)
+	}
+	for _, message := range params.Messages {
+		for _, content := range message.Content {
+			switch {
+			case content.OfText != nil:
+				fmt.Fprintf(t.out, "  %s: %s\n", message.Role, content.OfText.Text)
+			case content.OfImage != nil:
+				fmt.Fprintf(t.out, "  %s: %s\n", message.Role, describeImage(content.OfImage))
+			}
+		}
+	}
+
+	answer, err := t.ask(ctx, "Allow? [y/N] ")
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// ApproveToolCall shows a tool call Claude asks for and lets the user approve,
+// deny or edit it. Edited input must be a JSON object and is shown again for approval.
+func (t *terminal) ApproveToolCall(ctx context.Context, name string, input []byte) (domain.ToolCallDecision, error) {
+	defer t.acquire()()
+
+	edited := false
+	for {
+		fmt.Fprintf(t.out, "Claude wants to call %s with:\n%s\n", name, indentJSON(input))
+		answer, err := t.ask(ctx, "Approve? [y]es, [n]o, [e]dit: ")
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(t.out)
+			return domain.ToolCallDecision{Reason: "the user did not answer"}, nil
+		}
+		if err != nil {
+			return domain.ToolCallDecision{}, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			if edited {
+				return domain.ToolCallDecision{Approved: true, Input: input, User: userName()}, nil
+			}
+			return domain.ToolCallDecision{Approved: true, User: userName()}, nil
+		case "n", "no":
+			reason, err := t.ask(ctx, "Reason for Claude (optional): ")
+			if err != nil && !errors.Is(err, io.EOF) {
+				return domain.ToolCallDecision{}, err
+			}
+			return domain.ToolCallDecision{Reason: reason, User: userName()}, nil
+		case "e", "edit":
+			line, err := t.ask(ctx, "New input as JSON on one line: ")
+			if err != nil && !errors.Is(err, io.EOF) {
+				return domain.ToolCallDecision{}, err
+			}
+			var object map[string]any
+			if err := json.Unmarshal([]byte(line), &object); err != nil {
+				fmt.Fprintf(t.out, "  not a JSON object: %v\n", err)
+				continue
+			}
+			input, edited = []byte(line), true
+		}
+	}
+}
+
+// userName names the user at the terminal for the audit log
+func userName() string {
+	if current, err := osuser.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+// indentJSON formats JSON for reading, indented below the line before it
+func indentJSON(data []byte) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "  ", "  "); err != nil {
+		return "  " + string(data)
+	}
+	return "  " + indented.String()
+}
+
+// Elicit asks the user to fill in the form of a server field by field. An
+// invalid answer is explained and asked again.
+func (t *terminal) Elicit(ctx context.Context, server string, form domain.Form) (domain.FormResponse, error) {
+	defer t.acquire()()
+
+	fmt.Fprintf(t.out, "Server %s asks: %s\n", server, form.Message)
+	fmt.Fprintln(t.out, "Type /decline to refuse or /cancel to dismiss the request.")
+	values := make(map[string]any, len(form.Fields))
+	for _, field := range form.Fields {
+		printField(t.out, field)
+		for {
+			input, err := t.ask(ctx, fieldPrompt(field))
+			if errors.Is(err, io.EOF) {
+				fmt.Fprintln(t.out)
+				return domain.FormResponse{Action: domain.FormCancel}, nil
+			}
+			if err != nil {
+				return domain.FormResponse{}, err
+			}
+			switch input {
+			case "/decline":
+				return domain.FormResponse{Action: domain.FormDecline}, nil
+			case "/cancel":
+				return domain.FormResponse{Action: domain.FormCancel}, nil
+			}
+
+			value, err := field.Parse(input)
+			if err != nil {
+				fmt.Fprintf(t.out, "  %v\n", err)
+				continue
+			}
+			if value != nil {
+				values[field.Name] = value
+			}
+			break
+		}
+	}
+	return domain.FormResponse{Action: domain.FormAccept, Values: values}, nil
+}
+
+// printField describes a form field before it is asked for
+func printField(out io.Writer, field domain.FormField) {
+	label := field.Label()
+	if field.Required {
+		label += " (required)"
+	}
+	if field.Description != "" {
+		label += " - " + field.Description
+	}
+	fmt.Fprintf(out, "  %s\n", label)
+	for i, value := range field.Enum {
+		if i < len(field.EnumNames) {
+			fmt.Fprintf(out, "    %d. %s (%s)\n", i+1, field.EnumNames[i], value)
+		} else {
+			fmt.Fprintf(out, "    %d. %s\n", i+1, value)
+		}
+	}
+}
+
+// fieldPrompt is the input prompt of a field, showing the expected kind of answer and the default
+func fieldPrompt(field domain.FormField) string {
+	var hints []string
+	switch {
+	case field.Type == domain.FieldBoolean:
+		hints = append(hints, "y/n")
+	case field.Format != "":
+		hints = append(hints, field.Format)
+	case field.Type != domain.FieldString:
+		hints = append(hints, field.Type)
+	}
+	if field.Default != nil {
+		hints = append(hints, fmt.Sprintf("default %v", field.Default))
+	}
+	if len(hints) == 0 {
+		return "  > "
+	}
+	return fmt.Sprintf("  [%s] > ", strings.Join(hints, ", "))
+}
+
+// unattended answers for commands that cannot ask the user, so it allows nothing
+type unattended struct{}
+
+func (unattended) ApproveToolCall(ctx context.Context, name string, input []byte) (domain.ToolCallDecision, error) {
+	return domain.ToolCallDecision{}, fmt.Errorf("tool calls can only be approved in chat, set tools.%s.approval to allow to run it here", name)
+}
+
+func (unattended) ApproveSampling(ctx context.Context, server string, params anthropic.MessageNewParams, remaining int64) (bool, error) {
+	return false, fmt.Errorf("requests can only be approved in chat, set sampling.auto_approve to allow them here")
+}
+
+func (unattended) Elicit(ctx context.Context, server string, form domain.Form) (domain.FormResponse, error) {
+	log.Printf("Server %s asked for input, which only chat can give: %s", server, form.Message)
+	return domain.FormResponse{Action: domain.FormCancel}, nil
+}
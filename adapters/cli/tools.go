@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"mcp_client/adapters/mcp_servers"
+	"strings"
+)
+
+func runTools(args []string) int {
+	flags := newFlagSet("tools", "", "List the tools, resources and prompts of the configured servers.")
+	servers := addServerFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	cfg, err := loadConfig(flags, servers, nil)
+	if err != nil {
+		return fail(err)
+	}
+
+	registry, err := connect(cfg, nil, nil)
+	if err != nil {
+		return fail(err)
+	}
+	defer registry.Close()
+
+	for _, server := range registry.Servers() {
+		fmt.Printf("%s: %s (version %s)\n", server.Config.Name, server.Info.ServerInfo.Name, server.Info.ServerInfo.Version)
+		printSection("Tools", len(server.Tools))
+		for _, tool := range registry.Tools() {
+			if tool.Server == server {
+				fmt.Printf("    %s - %s\n", tool.Name, tool.Tool.Description)
+			}
+		}
+		printSection("Resources", len(server.Resources))
+		for _, resource := range registry.Resources() {
+			if resource.Server == server {
+				fmt.Printf("    %s (%s) - %s\n", resource.Name, resource.Resource.URI, resource.Resource.Description)
+			}
+		}
+		printSection("Resource templates", len(server.ResourceTemplates))
+		for _, template := range registry.ResourceTemplates() {
+			if template.Server == server {
+				fmt.Printf("    %s (%s) - %s\n", template.Name, template.Template.URITemplate.Raw(), template.Template.Description)
+			}
+		}
+		printSection("Prompts", len(server.Prompts))
+		for _, prompt := range server.Prompts {
+			fmt.Printf("    %s - %s\n", prompt.Name, prompt.Description)
+		}
+	}
+	return exitOK
+}
+
+func printSection(title string, count int) {
+	if count > 0 {
+		fmt.Printf("  %s:\n", title)
+	}
+}
+
+// reportListChange tells the user what the servers added to or removed from the catalog
+func reportListChange(change mcp_servers.ListChange) {
+	if len(change.Added) > 0 {
+		fmt.Printf("Servers added %s: %s\n", change.Kind, strings.Join(change.Added, ", "))
+	}
+	if len(change.Removed) > 0 {
+		fmt.Printf("Servers removed %s: %s\n", change.Kind, strings.Join(change.Removed, ", "))
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mcp_client/adapters/mcp_servers"
+	"mcp_client/core/domain"
+	"mcp_client/core/usecases/conversation"
+	"strings"
+	"unicode/utf8"
+)
+
+// watch lists the watched resources, or starts watching one
+func (c *slashCommands) watch(ctx context.Context, target string) error {
+	if target == "" {
+		uris := c.registry.Watches()
+		fmt.Printf("Watching %d resources\n", len(uris))
+		for _, uri := range uris {
+			fmt.Printf("  %s\n", uri)
+		}
+		return nil
+	}
+
+	watchCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	uri, err := c.registry.Watch(watchCtx, target)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Watching %s\n", uri)
+	return nil
+}
+
+// unwatch stops watching a resource
+func (c *slashCommands) unwatch(ctx context.Context, target string) error {
+	if target == "" {
+		return errors.New("usage: /unwatch <resource-uri>")
+	}
+
+	unwatchCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	uri, err := c.registry.Unwatch(unwatchCtx, target)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stopped watching %s\n", uri)
+	return nil
+}
+
+// reportResourceUpdates returns the handler for changes of watched resources.
+// Depending on the mode the change is printed or queued as a note for Claude,
+// with the diff cut to limit characters.
+func reportResourceUpdates(mode string, chat *conversation.ConversationUsecase, limit int) func(update mcp_servers.ResourceUpdate) {
+	return func(update mcp_servers.ResourceUpdate) {
+		if mode == domain.ResourceUpdatesConversation {
+			diff := update.Diff
+			if len(diff) > limit {
+				cut := limit
+				for cut > 0 && !utf8.RuneStart(diff[cut]) {
+					cut--
+				}
+				diff = diff[:cut] + "\n[...]"
+			}
+			chat.AddNote(fmt.Sprintf("The resource %s of server %s changed since it was last read:\n%s", update.URI, update.Server, diff))
+			fmt.Printf("\n%s changed, Claude will be told with your next message\n", update.URI)
+			return
+		}
+		fmt.Printf("\n%s changed:\n", update.URI)
+		for _, line := range strings.Split(update.Diff, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
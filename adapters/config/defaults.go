@@ -0,0 +1,55 @@
+package config
+
+import (
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// DefaultSystemPrompt is used when no system prompt is configured
+const DefaultSystemPrompt = "You are a helpful assistant that can use the tools provided to you. To manage a customer database"
+
+// DefaultServers is used when no servers are configured, keeping the single
+// customer server setup working without any configuration.
+var DefaultServers = []domain.ServerConfig{
+	{Name: "crm", URL: "http://localhost:8080/mcp"},
+}
+
+// DefaultClientConfig returns the configuration used when nothing is overridden
+func DefaultClientConfig() domain.ClientConfig {
+	return domain.ClientConfig{
+		Servers: DefaultServers,
+		Chat: domain.ChatSettings{
+			Model:        string(anthropic.ModelClaude3_7SonnetLatest),
+			MaxTokens:    1024,
+			SystemPrompt: DefaultSystemPrompt,
+			ModelTimeout: 30 * time.Second,
+			ToolTimeout:  30 * time.Second,
+			// The context window of every current Claude model
+			ContextWindow:    200000,
+			CompactThreshold: 0.8,
+			ToolResultLimit:  2000,
+		},
+		// The sampling model and timeout follow the chat settings, see loadConfig in the cli
+		Sampling: domain.SamplingSettings{
+			MaxTokens:   1024,
+			TokenBudget: 20000,
+		},
+		ConnectTimeout:  30 * time.Second,
+		SessionsDir:     DefaultSessionsDir(),
+		ResourceUpdates: domain.ResourceUpdatesShow,
+	}
+}
+
+// DefaultSessionsDir is ~/.mcp_client/sessions, or a directory relative to the
+// working directory when there is no home directory.
+func DefaultSessionsDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".mcp_client", "sessions")
+	}
+	return filepath.Join(home, ".mcp_client", "sessions")
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFiles are looked for in the working directory, in order, when no config file is given
+var DefaultConfigFiles = []string{"mcp_client.yaml", "mcp_client.yml", "mcp_client.json"}
+
+// Environment variables overriding the config file
+const (
+	EnvConfigFile     = "MCP_CLIENT_CONFIG"
+	EnvModel          = "MCP_CLIENT_MODEL"
+	EnvMaxTokens      = "MCP_CLIENT_MAX_TOKENS"
+	EnvSystemPrompt   = "MCP_CLIENT_SYSTEM_PROMPT"
+	EnvConnectTimeout = "MCP_CLIENT_CONNECT_TIMEOUT"
+	EnvModelTimeout   = "MCP_CLIENT_MODEL_TIMEOUT"
+	EnvToolTimeout    = "MCP_CLIENT_TOOL_TIMEOUT"
+	EnvSessionsDir    = "MCP_CLIENT_SESSIONS_DIR"
+	EnvAPIKey         = "ANTHROPIC_API_KEY"
+)
+
+// fileConfig is the layout of the config file. YAML is a superset of JSON, so
+// both formats are read with the YAML decoder. Pointers tell unset keys apart.
+type fileConfig struct {
+	Servers          []domain.ServerConfig        `yaml:"servers"`
+	Tools            map[string]domain.ToolConfig `yaml:"tools"`
+	Model            *string                      `yaml:"model"`
+	MaxTokens        *int64                       `yaml:"max_tokens"`
+	SystemPrompt     *string                      `yaml:"system_prompt"`
+	SystemPromptFile *string                      `yaml:"system_prompt_file"`
+	SessionsDir      *string                      `yaml:"sessions_dir"`
+	Roots            []string                     `yaml:"roots"`
+	ResourceUpdates  *string                      `yaml:"resource_updates"`
+	Timeouts         struct {
+		Connect *duration `yaml:"connect"`
+		Model   *duration `yaml:"model"`
+		Tool    *duration `yaml:"tool"`
+	} `yaml:"timeouts"`
+	Context struct {
+		Window          *int64   `yaml:"window"`
+		CompactAt       *float64 `yaml:"compact_at"`
+		ToolResultLimit *int     `yaml:"tool_result_limit"`
+	} `yaml:"context"`
+	Audit struct {
+		File   *string  `yaml:"file"`
+		Redact []string `yaml:"redact"`
+	} `yaml:"audit"`
+	Sampling struct {
+		Model       *string  `yaml:"model"`
+		Models      []string `yaml:"models"`
+		MaxTokens   *int64   `yaml:"max_tokens"`
+		TokenBudget *int64   `yaml:"token_budget"`
+		AutoApprove *bool    `yaml:"auto_approve"`
+	} `yaml:"sampling"`
+}
+
+// duration reads durations written as strings like "30s" or "2m"
+type duration time.Duration
+
+func (d *duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", node.Line, node.Value)
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// Load builds the client configuration by layering, in increasing precedence,
+// the defaults, the config file, the .env file and the real environment.
+// path names the config file; when empty MCP_CLIENT_CONFIG or the first
+// existing DefaultConfigFiles entry is used, and having none is fine.
+// The result is not validated so callers can apply flags first.
+func Load(path string) (domain.ClientConfig, error) {
+	cfg := DefaultClientConfig()
+
+	if err := LoadDotEnv(DefaultDotEnvFile); err != nil {
+		return cfg, err
+	}
+
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path == "" {
+		path = findDefaultConfigFile()
+	}
+	if path != "" {
+		if err := applyFile(&cfg, path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func findDefaultConfigFile() string {
+	for _, candidate := range DefaultConfigFiles {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func applyFile(cfg *domain.ClientConfig, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if file.Servers != nil {
+		cfg.Servers = file.Servers
+	}
+	if file.Tools != nil {
+		cfg.Chat.Tools = file.Tools
+	}
+	if file.Model != nil {
+		cfg.Chat.Model = *file.Model
+	}
+	if file.MaxTokens != nil {
+		cfg.Chat.MaxTokens = *file.MaxTokens
+	}
+	if file.SystemPrompt != nil && file.SystemPromptFile != nil {
+		return fmt.Errorf("%s: system_prompt and system_prompt_file cannot both be set", path)
+	}
+	if file.SystemPrompt != nil {
+		cfg.Chat.SystemPrompt = *file.SystemPrompt
+	}
+	if file.SystemPromptFile != nil {
+		systemPrompt, err := ReadSystemPrompt(relativeTo(path, *file.SystemPromptFile))
+		if err != nil {
+			return fmt.Errorf("%s: system_prompt_file: %w", path, err)
+		}
+		cfg.Chat.SystemPrompt = systemPrompt
+	}
+	if file.SessionsDir != nil {
+		cfg.SessionsDir = *file.SessionsDir
+	}
+	if file.Roots != nil {
+		cfg.Roots = make([]string, len(file.Roots))
+		for i, root := range file.Roots {
+			cfg.Roots[i] = relativeTo(path, root)
+		}
+	}
+	if file.ResourceUpdates != nil {
+		cfg.ResourceUpdates = *file.ResourceUpdates
+	}
+	if file.Context.Window != nil {
+		cfg.Chat.ContextWindow = *file.Context.Window
+	}
+	if file.Context.CompactAt != nil {
+		cfg.Chat.CompactThreshold = *file.Context.CompactAt
+	}
+	if file.Context.ToolResultLimit != nil {
+		cfg.Chat.ToolResultLimit = *file.Context.ToolResultLimit
+	}
+	if file.Audit.File != nil {
+		cfg.Audit.File = relativeTo(path, *file.Audit.File)
+	}
+	if file.Audit.Redact != nil {
+		cfg.Audit.Redact = file.Audit.Redact
+	}
+	if file.Sampling.Model != nil {
+		cfg.Sampling.Model = *file.Sampling.Model
+	}
+	if file.Sampling.Models != nil {
+		cfg.Sampling.Models = file.Sampling.Models
+	}
+	if file.Sampling.MaxTokens != nil {
+		cfg.Sampling.MaxTokens = *file.Sampling.MaxTokens
+	}
+	if file.Sampling.TokenBudget != nil {
+		cfg.Sampling.TokenBudget = *file.Sampling.TokenBudget
+	}
+	if file.Sampling.AutoApprove != nil {
+		cfg.Sampling.AutoApprove = *file.Sampling.AutoApprove
+	}
+	if file.Timeouts.Connect != nil {
+		cfg.ConnectTimeout = time.Duration(*file.Timeouts.Connect)
+	}
+	if file.Timeouts.Model != nil {
+		cfg.Chat.ModelTimeout = time.Duration(*file.Timeouts.Model)
+	}
+	if file.Timeouts.Tool != nil {
+		cfg.Chat.ToolTimeout = time.Duration(*file.Timeouts.Tool)
+	}
+	return nil
+}
+
+// relativeTo resolves a path from the config file against the directory of the file
+func relativeTo(configPath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configPath), path)
+}
+
+func applyEnv(cfg *domain.ClientConfig) error {
+	if value, ok := os.LookupEnv(EnvModel); ok {
+		cfg.Chat.Model = value
+	}
+	if value, ok := os.LookupEnv(EnvMaxTokens); ok {
+		maxTokens, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", EnvMaxTokens, value)
+		}
+		cfg.Chat.MaxTokens = maxTokens
+	}
+	if value, ok := os.LookupEnv(EnvSystemPrompt); ok {
+		cfg.Chat.SystemPrompt = value
+	}
+	if value, ok := os.LookupEnv(EnvSessionsDir); ok {
+		cfg.SessionsDir = value
+	}
+	for name, target := range map[string]*time.Duration{
+		EnvConnectTimeout: &cfg.ConnectTimeout,
+		EnvModelTimeout:   &cfg.Chat.ModelTimeout,
+		EnvToolTimeout:    &cfg.Chat.ToolTimeout,
+	} {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: invalid duration %q", name, value)
+			}
+			*target = parsed
+		}
+	}
+	cfg.APIKey = os.Getenv(EnvAPIKey)
+	return nil
+}
+
+// ReadSystemPrompt reads a system prompt from a file
+func ReadSystemPrompt(path string) (string, error) {
+	systemPrompt, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read system prompt: %w", err)
+	}
+	return strings.TrimSpace(string(systemPrompt)), nil
+}
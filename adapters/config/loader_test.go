@@ -0,0 +1,190 @@
+package config
+
+import (
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFile(t, dir, "mcp_client.yaml", `
+servers:
+  - name: billing
+    url: http://localhost:8081/mcp
+model: file-model
+max_tokens: 2048
+timeouts:
+  tool: 1m
+`)
+	writeFile(t, dir, ".env", `
+export MCP_CLIENT_MODEL="dotenv-model" # overridden by the real environment
+MCP_CLIENT_MAX_TOKENS=4096
+`)
+	t.Setenv(EnvModel, "env-model")
+	// Let the .env value through while still restoring the environment afterwards
+	t.Setenv(EnvMaxTokens, "")
+	os.Unsetenv(EnvMaxTokens)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	if len(cfg.Servers) != 1 || cfg.Servers[0].Name != "billing" {
+		t.Errorf("expected servers from the file but got: %+v", cfg.Servers)
+	}
+	if cfg.Chat.Model != "env-model" {
+		t.Errorf("expected the environment to win over .env and the file, got model %q", cfg.Chat.Model)
+	}
+	if cfg.Chat.MaxTokens != 4096 {
+		t.Errorf("expected .env to win over the file, got max tokens %d", cfg.Chat.MaxTokens)
+	}
+	if cfg.Chat.ToolTimeout != time.Minute {
+		t.Errorf("expected tool timeout from the file, got %s", cfg.Chat.ToolTimeout)
+	}
+	if cfg.Chat.ModelTimeout != DefaultClientConfig().Chat.ModelTimeout {
+		t.Errorf("expected the default model timeout, got %s", cfg.Chat.ModelTimeout)
+	}
+}
+
+func TestLoad_ResolvesRootsAgainstConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "client.yaml", `
+roots: [imports, /data/csv]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	expected := []string{filepath.Join(dir, "imports"), "/data/csv"}
+	if len(cfg.Roots) != 2 || cfg.Roots[0] != expected[0] || cfg.Roots[1] != expected[1] {
+		t.Errorf("expected roots %v but got %v", expected, cfg.Roots)
+	}
+}
+
+func TestLoad_WithoutFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("expected the defaults to be valid but got: %v", err)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		env         map[string]string
+		expectError string
+	}{
+		{
+			name:        "unknown key",
+			config:      "modle: claude\n",
+			expectError: "field modle not found",
+		},
+		{
+			name:        "invalid duration",
+			config:      "timeouts:\n  model: soon\n",
+			expectError: `invalid duration "soon"`,
+		},
+		{
+			name:        "invalid environment variable",
+			env:         map[string]string{EnvMaxTokens: "lots"},
+			expectError: EnvMaxTokens,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			writeFile(t, dir, "mcp_client.yaml", tt.config)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load("")
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("expected error containing %q but got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidate_NamesOffendingKeys(t *testing.T) {
+	cfg := DefaultClientConfig()
+	cfg.Servers = append(cfg.Servers, cfg.Servers[0])
+	cfg.Servers[1].URL = ""
+	cfg.Chat.MaxTokens = 0
+	cfg.Chat.CompactThreshold = 1.5
+	cfg.Sampling.TokenBudget = -1
+	cfg.ResourceUpdates = "popup"
+	cfg.Audit.Redact = []string{"crm__register_customer."}
+	cfg.Chat.Tools = map[string]domain.ToolConfig{"crm__delete_customer": {Approval: "sometimes", AskIf: "("}}
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("expected error but got none")
+	}
+	for _, key := range []string{"servers[1].name", "servers[1].url", "max_tokens", "context.compact_at", "sampling.token_budget", "resource_updates",
+		"tools.crm__delete_customer.approval", "tools.crm__delete_customer.ask_if", "audit.redact[0]"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected the error to name %s but got: %v", key, err)
+		}
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	variables, err := ParseDotEnv(`
+# comment
+PLAIN=value
+export EXPORTED=yes
+INLINE=value # comment
+HASH=a#b
+SINGLE='literal \n # kept'
+DOUBLE="line\nbreak \"quoted\"" # comment
+EMPTY=
+`)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	expected := map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "yes",
+		"INLINE":   "value",
+		"HASH":     "a#b",
+		"SINGLE":   `literal \n # kept`,
+		"DOUBLE":   "line\nbreak \"quoted\"",
+		"EMPTY":    "",
+	}
+	for key, value := range expected {
+		if variables[key] != value {
+			t.Errorf("expected %s=%q but got %q", key, value, variables[key])
+		}
+	}
+
+	if _, err := ParseDotEnv(`BROKEN="unterminated`); err == nil {
+		t.Errorf("expected error for an unterminated quote")
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"mcp_client/core/domain"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Validate checks the configuration and reports every problem, each naming the offending key
+func Validate(cfg domain.ClientConfig) error {
+	var problems []error
+	problem := func(key, format string, args ...any) {
+		problems = append(problems, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if len(cfg.Servers) == 0 {
+		problem("servers", "at least one server is required")
+	}
+	names := make(map[string]bool, len(cfg.Servers))
+	for i, server := range cfg.Servers {
+		key := fmt.Sprintf("servers[%d]", i)
+		if server.Name == "" {
+			problem(key+".name", "is required")
+		} else if names[server.Name] {
+			problem(key+".name", "duplicate server name %q", server.Name)
+		}
+		names[server.Name] = true
+
+		switch server.TransportKind() {
+		case domain.TransportStreamableHTTP, domain.TransportSSE:
+			if server.URL == "" {
+				problem(key+".url", "is required for the %s transport", server.TransportKind())
+			}
+		case domain.TransportStdio:
+			if server.Command == "" {
+				problem(key+".command", "is required for the stdio transport")
+			}
+		default:
+			problem(key+".transport", "unknown transport %q, expected http, sse or stdio", server.Transport)
+		}
+
+		if server.MaxConcurrency < 0 {
+			problem(key+".max_concurrency", "must not be negative, got %d", server.MaxConcurrency)
+		}
+		if server.MaxListPages < 0 {
+			problem(key+".max_list_pages", "must not be negative, got %d", server.MaxListPages)
+		}
+	}
+
+	for name, tool := range cfg.Chat.Tools {
+		if !strings.Contains(name, domain.NamespaceSeparator) {
+			problem("tools."+name, "tools are configured by namespaced name, e.g. crm%s%s", domain.NamespaceSeparator, name)
+		}
+		switch tool.Approval {
+		case "", domain.ApprovalAllow, domain.ApprovalAsk, domain.ApprovalDeny:
+		default:
+			problem("tools."+name+".approval", "unknown policy %q, expected allow, ask or deny", tool.Approval)
+		}
+		if _, err := regexp.Compile(tool.AskIf); err != nil {
+			problem("tools."+name+".ask_if", "%v", err)
+		}
+	}
+
+	if cfg.Chat.Model == "" {
+		problem("model", "is required")
+	}
+	if cfg.Chat.MaxTokens <= 0 {
+		problem("max_tokens", "must be positive, got %d", cfg.Chat.MaxTokens)
+	}
+	if cfg.Chat.ContextWindow < 0 {
+		problem("context.window", "must not be negative, got %d", cfg.Chat.ContextWindow)
+	}
+	if cfg.Chat.CompactThreshold <= 0 || cfg.Chat.CompactThreshold > 1 {
+		problem("context.compact_at", "must be a fraction of the context window between 0 and 1, got %g", cfg.Chat.CompactThreshold)
+	}
+	if cfg.Chat.ToolResultLimit <= 0 {
+		problem("context.tool_result_limit", "must be positive, got %d", cfg.Chat.ToolResultLimit)
+	}
+	if cfg.Sampling.MaxTokens <= 0 {
+		problem("sampling.max_tokens", "must be positive, got %d", cfg.Sampling.MaxTokens)
+	}
+	if cfg.Sampling.TokenBudget < 0 {
+		problem("sampling.token_budget", "must not be negative, got %d", cfg.Sampling.TokenBudget)
+	}
+	for i, root := range cfg.Roots {
+		if info, err := os.Stat(root); err != nil {
+			problem(fmt.Sprintf("roots[%d]", i), "%v", err)
+		} else if !info.IsDir() {
+			problem(fmt.Sprintf("roots[%d]", i), "%s is not a directory", root)
+		}
+	}
+	for i, rule := range cfg.Audit.Redact {
+		if rule == "" || strings.HasSuffix(rule, ".") {
+			problem(fmt.Sprintf("audit.redact[%d]", i), "expected an argument name, optionally after a tool name and a dot, got %q", rule)
+		}
+	}
+	switch cfg.ResourceUpdates {
+	case domain.ResourceUpdatesShow, domain.ResourceUpdatesConversation:
+	default:
+		problem("resource_updates", "unknown mode %q, expected %s or %s", cfg.ResourceUpdates, domain.ResourceUpdatesShow, domain.ResourceUpdatesConversation)
+	}
+	if cfg.SessionsDir == "" {
+		problem("sessions_dir", "is required")
+	}
+	if cfg.ConnectTimeout <= 0 {
+		problem("timeouts.connect", "must be positive, got %s", cfg.ConnectTimeout)
+	}
+	if cfg.Chat.ModelTimeout <= 0 {
+		problem("timeouts.model", "must be positive, got %s", cfg.Chat.ModelTimeout)
+	}
+	if cfg.Chat.ToolTimeout <= 0 {
+		problem("timeouts.tool", "must be positive, got %s", cfg.Chat.ToolTimeout)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(problems...))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package mcp_servers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mcp_client/core/domain"
+	"regexp"
+	"runtime/debug"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ClientName is the name the client introduces itself with to servers
+const ClientName = "mcp_client"
+
+// ClientVersion is the version the client reports to servers. Release builds
+// set it with -ldflags "-X mcp_client/adapters/mcp_servers.ClientVersion=...";
+// otherwise the module version from the build info is used.
+var ClientVersion = ""
+
+// clientErrorStatus matches the 4xx statuses the Streamable HTTP transport reports in its errors
+var clientErrorStatus = regexp.MustCompile(`(?:status |\()4\d\d\b`)
+
+// authErrorStatus matches 401 and 403, which a server needing credentials
+// answers whatever transport it speaks, so they do not warrant trying SSE
+var authErrorStatus = regexp.MustCompile(`(?:status |\()40[13]\b`)
+
+// Dialer connects to servers using their configured transport. For URL servers
+// without an explicit transport it falls back from Streamable HTTP to the legacy
+// SSE transport and remembers which one worked, so reconnects go straight to it.
+type Dialer struct {
+	mu        sync.Mutex
+	transport map[string]string
+	sampler   Sampler
+	roots     *Roots
+	elicitor  Elicitor
+}
+
+// DialerOption configures a Dialer
+type DialerOption func(*Dialer)
+
+// WithSampler lets servers request model completions through sampler
+func WithSampler(sampler Sampler) DialerOption {
+	return func(d *Dialer) {
+		d.sampler = sampler
+	}
+}
+
+// WithRoots tells servers which local directories they may work in
+func WithRoots(roots *Roots) DialerOption {
+	return func(d *Dialer) {
+		d.roots = roots
+	}
+}
+
+// WithElicitor lets servers ask the user for input through elicitor
+func WithElicitor(elicitor Elicitor) DialerOption {
+	return func(d *Dialer) {
+		d.elicitor = elicitor
+	}
+}
+
+// NewDialer creates a new Dialer instance
+func NewDialer(options ...DialerOption) *Dialer {
+	d := &Dialer{
+		transport: make(map[string]string),
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Connect creates, starts and initializes a client for the server. It is a Connector.
+func (d *Dialer) Connect(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error) {
+	kind := config.TransportKind()
+	if config.AllowsSSEFallback() {
+		kind = d.rememberedTransport(config.Name, kind)
+	}
+
+	options := d.clientOptions(config)
+	switch kind {
+	case domain.TransportStreamableHTTP:
+		mcpClient, serverInfo, err := connectStreamableHTTP(ctx, config, options)
+		if err != nil && config.AllowsSSEFallback() && clientErrorStatus.MatchString(err.Error()) && !authErrorStatus.MatchString(err.Error()) {
+			log.Printf("Server %s rejected Streamable HTTP (%v), falling back to SSE", config.Name, err)
+			sseClient, sseInfo, sseErr := connectSSE(ctx, config, options)
+			if sseErr != nil {
+				// Either error may be the one that explains the failure
+				return nil, nil, errors.Join(err, fmt.Errorf("SSE fallback: %w", sseErr))
+			}
+			d.remember(config.Name, domain.TransportSSE)
+			return sseClient, sseInfo, nil
+		}
+		if err == nil && config.AllowsSSEFallback() {
+			d.remember(config.Name, domain.TransportStreamableHTTP)
+		}
+		return mcpClient, serverInfo, err
+	case domain.TransportSSE:
+		return connectSSE(ctx, config, options)
+	case domain.TransportStdio:
+		return connectStdio(ctx, config, options)
+	default:
+		return nil, nil, fmt.Errorf("unsupported transport %q", config.Transport)
+	}
+}
+
+// clientOptions returns the options of the client for a server, installing
+// handlers for the requests the server may send to the client
+func (d *Dialer) clientOptions(config domain.ServerConfig) []client.ClientOption {
+	var options []client.ClientOption
+	if d.sampler != nil {
+		options = append(options, client.WithSamplingHandler(&samplingHandler{server: config.Name, sampler: d.sampler}))
+	}
+	if d.roots != nil {
+		options = append(options, client.WithRootsHandler(d.roots))
+	}
+	if d.elicitor != nil {
+		options = append(options, client.WithElicitationHandler(&elicitationHandler{server: config.Name, elicitor: d.elicitor}))
+	}
+	return options
+}
+
+func (d *Dialer) rememberedTransport(serverName, fallback string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if kind, exists := d.transport[serverName]; exists {
+		return kind
+	}
+	return fallback
+}
+
+func (d *Dialer) remember(serverName, kind string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.transport[serverName] = kind
+}
+
+func connectStreamableHTTP(ctx context.Context, config domain.ServerConfig, options []client.ClientOption) (*client.Client, *mcp.InitializeResult, error) {
+	// Without a standing GET stream, notifications such as list_changed and
+	// requests such as roots/list only reach the client while a request is in flight
+	httpTransport, err := transport.NewStreamableHTTP(config.URL, transport.WithContinuousListening())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create HTTP transport: %w", err)
+	}
+
+	mcpClient := client.NewClient(httpTransport, options...)
+	// The listening stream is bound to the context the client is started with,
+	// so it must outlive ctx, which only covers the handshake. Close ends it.
+	if err := mcpClient.Start(context.WithoutCancel(ctx)); err != nil {
+		return nil, nil, fmt.Errorf("failed to start client: %w", err)
+	}
+
+	return initialize(ctx, mcpClient)
+}
+
+func connectSSE(ctx context.Context, config domain.ServerConfig, options []client.ClientOption) (*client.Client, *mcp.InitializeResult, error) {
+	sseTransport, err := transport.NewSSE(config.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create SSE transport: %w", err)
+	}
+
+	mcpClient := client.NewClient(sseTransport, options...)
+	// The event stream is bound to the context it is opened with, so it must
+	// outlive ctx, which only covers the handshake. Close ends it.
+	if err := mcpClient.Start(context.WithoutCancel(ctx)); err != nil {
+		return nil, nil, fmt.Errorf("failed to start SSE client: %w", err)
+	}
+
+	return initialize(ctx, mcpClient)
+}
+
+func connectStdio(ctx context.Context, config domain.ServerConfig, options []client.ClientOption) (*client.Client, *mcp.InitializeResult, error) {
+	stdioTransport := transport.NewStdio(config.Command, config.EnvList(), config.Args...)
+
+	mcpClient := client.NewClient(stdioTransport, options...)
+	// The subprocess is bound to the context it is started with, so it must
+	// outlive ctx, which only covers the handshake. Close stops it.
+	if err := mcpClient.Start(context.WithoutCancel(ctx)); err != nil {
+		return nil, nil, fmt.Errorf("failed to start %s: %w", config.Command, err)
+	}
+
+	return initialize(ctx, mcpClient)
+}
+
+func initialize(ctx context.Context, mcpClient *client.Client) (*client.Client, *mcp.InitializeResult, error) {
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    ClientName,
+		Version: clientVersion(),
+	}
+	initRequest.Params.Capabilities = mcp.ClientCapabilities{}
+
+	serverInfo, err := mcpClient.Initialize(ctx, initRequest)
+	if err != nil {
+		mcpClient.Close()
+		return nil, nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	return mcpClient, serverInfo, nil
+}
+
+// clientVersion returns ClientVersion, falling back to the version of the main
+// module for binaries built with go install
+func clientVersion() string {
+	if ClientVersion != "" {
+		return ClientVersion
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
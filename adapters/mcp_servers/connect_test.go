@@ -0,0 +1,122 @@
+package mcp_servers
+
+import (
+	"context"
+	"mcp_client/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestDialer_FallsBackToSSE(t *testing.T) {
+	var calls int
+	sseServer := server.NewTestServer(newTestServer(&calls))
+	defer sseServer.Close()
+
+	dialer := NewDialer()
+	config := *domain.NewServerConfig("legacy", sseServer.URL+"/sse")
+
+	mcpClient, _, err := dialer.Connect(context.Background(), config)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	mcpClient.Close()
+
+	if kind := dialer.rememberedTransport("legacy", ""); kind != domain.TransportSSE {
+		t.Errorf("expected the dialer to remember %q but got %q", domain.TransportSSE, kind)
+	}
+}
+
+func TestDialer_ExplicitTransportDoesNotFallBack(t *testing.T) {
+	var calls int
+	sseServer := server.NewTestServer(newTestServer(&calls))
+	defer sseServer.Close()
+
+	config := *domain.NewServerConfig("legacy", sseServer.URL+"/sse")
+	config.Transport = domain.TransportStreamableHTTP
+
+	_, _, err := NewDialer().Connect(context.Background(), config)
+	if err == nil {
+		t.Errorf("expected error but got none")
+	}
+}
+
+func TestDialer_FallbackKeepsTheStreamableHTTPError(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		expectMethods string
+		expectErrors  []string
+	}{
+		{
+			name:          "auth errors do not fall back",
+			status:        http.StatusUnauthorized,
+			expectMethods: "POST",
+			expectErrors:  []string{"status 401"},
+		},
+		{
+			name:          "failed fallback",
+			status:        http.StatusMethodNotAllowed,
+			expectMethods: "POST,GET",
+			expectErrors:  []string{"status 405", "SSE fallback"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var methods []string
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				methods = append(methods, r.Method)
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+			}))
+			defer httpServer.Close()
+
+			_, _, err := NewDialer().Connect(context.Background(), *domain.NewServerConfig("crm", httpServer.URL+"/mcp"))
+
+			if err == nil {
+				t.Fatalf("expected an error but got none")
+			}
+			for _, expected := range tt.expectErrors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected the error to contain %q but got: %v", expected, err)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Join(methods, ",") != tt.expectMethods {
+				t.Errorf("expected requests %s but got %v", tt.expectMethods, methods)
+			}
+		})
+	}
+}
+
+func TestDialer_IntroducesTheClient(t *testing.T) {
+	var clientInfo mcp.Implementation
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		clientInfo = request.Params.ClientInfo
+	})
+	httpServer := server.NewTestStreamableHTTPServer(server.NewMCPServer("crm", "1.0.0", server.WithHooks(hooks)))
+	defer httpServer.Close()
+
+	ClientVersion = "1.2.3"
+	defer func() { ClientVersion = "" }()
+
+	mcpClient, _, err := NewDialer().Connect(context.Background(), *domain.NewServerConfig("crm", httpServer.URL+"/mcp"))
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	mcpClient.Close()
+
+	if clientInfo.Name != ClientName || clientInfo.Version != "1.2.3" {
+		t.Errorf("expected the client to introduce itself as %s 1.2.3 but got %s %s", ClientName, clientInfo.Name, clientInfo.Version)
+	}
+}
@@ -0,0 +1,80 @@
+package mcp_servers
+
+import "strings"
+
+// maxDiffCells bounds the work of diffLines; larger changes are shown as replaced wholesale
+const maxDiffCells = 1_000_000
+
+// diffLines returns the lines removed from before and added in after, in
+// order and prefixed with "- " and "+ ". Unchanged lines are left out, so
+// equal texts give an empty diff.
+func diffLines(before, after string) string {
+	if before == after {
+		return ""
+	}
+	a, b := lines(before), lines(after)
+
+	// Lines both texts start or end with need no comparing
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	var diff strings.Builder
+	line := func(prefix, text string) {
+		diff.WriteString(prefix)
+		diff.WriteString(text)
+		diff.WriteByte('\n')
+	}
+	if len(a)*len(b) > maxDiffCells {
+		for _, text := range a {
+			line("- ", text)
+		}
+		for _, text := range b {
+			line("+ ", text)
+		}
+		return strings.TrimSuffix(diff.String(), "\n")
+	}
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			// Removed lines come before the lines replacing them
+			line("- ", a[i])
+			i++
+		default:
+			line("+ ", b[j])
+			j++
+		}
+	}
+	return strings.TrimSuffix(diff.String(), "\n")
+}
+
+// lines splits text into lines; empty text has none
+func lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,124 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mcp_client/core/domain"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Elicitor asks the user to fill in the forms servers send
+type Elicitor interface {
+	Elicit(ctx context.Context, server string, form domain.Form) (domain.FormResponse, error)
+}
+
+// elicitationHandler answers the elicitation requests of one server
+type elicitationHandler struct {
+	server   string
+	elicitor Elicitor
+}
+
+var _ client.ElicitationHandler = (*elicitationHandler)(nil)
+
+// Elicit handles elicitation/create. A schema the client cannot render as a
+// form is returned to the server as a JSON-RPC error.
+func (h *elicitationHandler) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	form, err := convertRequestedSchema(request.Params.Message, request.Params.RequestedSchema)
+	if err != nil {
+		log.Printf("Cannot show the form of %s: %v", h.server, err)
+		return nil, err
+	}
+
+	response, err := h.elicitor.Elicit(ctx, h.server, form)
+	if err != nil {
+		return nil, err
+	}
+	result := &mcp.ElicitationResult{
+		ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseAction(response.Action)},
+	}
+	if response.Action == domain.FormAccept {
+		result.Content = response.Values
+	}
+	return result, nil
+}
+
+// requestedSchema is the restricted JSON schema of an elicitation request: an
+// object whose properties are strings, numbers, integers or booleans
+type requestedSchema struct {
+	Type       string                       `json:"type"`
+	Properties map[string]requestedProperty `json:"properties"`
+	Required   []string                     `json:"required"`
+}
+
+type requestedProperty struct {
+	Type        string   `json:"type"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Default     any      `json:"default"`
+	Enum        []string `json:"enum"`
+	EnumNames   []string `json:"enumNames"`
+	Format      string   `json:"format"`
+	MinLength   *int     `json:"minLength"`
+	MaxLength   *int     `json:"maxLength"`
+	Minimum     *float64 `json:"minimum"`
+	Maximum     *float64 `json:"maximum"`
+}
+
+// convertRequestedSchema turns the schema of an elicitation request into a
+// form. mcp-go decodes the schema into a map, losing the order the server
+// listed the properties in, so the fields are ordered by name.
+func convertRequestedSchema(message string, schema any) (domain.Form, error) {
+	form := domain.Form{Message: message}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return form, fmt.Errorf("invalid requested schema: %w", err)
+	}
+	var object requestedSchema
+	if err := json.Unmarshal(data, &object); err != nil {
+		return form, fmt.Errorf("invalid requested schema: %w", err)
+	}
+	if object.Type != "object" {
+		return form, fmt.Errorf("requested schema must be an object, got %q", object.Type)
+	}
+
+	names := make([]string, 0, len(object.Properties))
+	for name := range object.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := make(map[string]bool, len(object.Required))
+	for _, name := range object.Required {
+		required[name] = true
+	}
+	for _, name := range names {
+		property := object.Properties[name]
+		switch property.Type {
+		case domain.FieldString, domain.FieldNumber, domain.FieldInteger, domain.FieldBoolean:
+		default:
+			return form, fmt.Errorf("property %s has unsupported type %q", name, property.Type)
+		}
+		form.Fields = append(form.Fields, domain.FormField{
+			Name:        name,
+			Title:       property.Title,
+			Description: property.Description,
+			Type:        property.Type,
+			Required:    required[name],
+			Default:     property.Default,
+			Enum:        property.Enum,
+			EnumNames:   property.EnumNames,
+			Format:      property.Format,
+			MinLength:   property.MinLength,
+			MaxLength:   property.MaxLength,
+			Minimum:     property.Minimum,
+			Maximum:     property.Maximum,
+		})
+	}
+	return form, nil
+}
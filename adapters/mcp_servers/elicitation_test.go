@@ -0,0 +1,166 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp_client/core/domain"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mockElicitor answers every form with the same response and records the forms it was shown
+type mockElicitor struct {
+	response domain.FormResponse
+	forms    []domain.Form
+}
+
+func (m *mockElicitor) Elicit(ctx context.Context, server string, form domain.Form) (domain.FormResponse, error) {
+	m.forms = append(m.forms, form)
+	return m.response, nil
+}
+
+func TestRegistry_AnswersElicitationRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   domain.FormResponse
+		expectText string
+	}{
+		{
+			name:       "accepted",
+			response:   domain.FormResponse{Action: domain.FormAccept, Values: map[string]any{"email": "ada@example.com"}},
+			expectText: `accept {"email":"ada@example.com"}`,
+		},
+		{
+			name:       "declined",
+			response:   domain.FormResponse{Action: domain.FormDecline},
+			expectText: "decline null",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpServer := server.NewMCPServer("crm", "1.0.0", server.WithToolCapabilities(false), server.WithElicitation())
+			mcpServer.AddTool(mcp.NewTool("register_customer"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				result, err := mcpServer.RequestElicitation(ctx, mcp.ElicitationRequest{
+					Params: mcp.ElicitationParams{
+						Message: "The customer has no email address",
+						RequestedSchema: json.RawMessage(`{
+							"type": "object",
+							"properties": {
+								"email": {"type": "string", "format": "email"},
+								"plan": {"type": "string", "enum": ["free", "pro"]},
+								"seats": {"type": "integer", "minimum": 1}
+							},
+							"required": ["email", "seats"]
+						}`),
+					},
+				})
+				if err != nil {
+					return nil, err
+				}
+				content, _ := json.Marshal(result.Content)
+				return mcp.NewToolResultText(string(result.Action) + " " + string(content)), nil
+			})
+
+			elicitor := &mockElicitor{response: tt.response}
+			connect := func(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error) {
+				handler := &elicitationHandler{server: config.Name, elicitor: elicitor}
+				inProcess := transport.NewInProcessTransportWithOptions(mcpServer, transport.WithElicitationHandler(handler))
+				mcpClient := client.NewClient(inProcess, client.WithElicitationHandler(handler))
+				if err := mcpClient.Start(ctx); err != nil {
+					return nil, nil, err
+				}
+				return initialize(ctx, mcpClient)
+			}
+			registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process"}}, connect)
+			if err := registry.Start(context.Background()); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			defer registry.Close()
+
+			result := registry.CallTool(context.Background(), "crm__register_customer", []byte(`{}`))
+
+			if text := result.Content[0].OfText.Text; text != tt.expectText {
+				t.Errorf("expected %q but got %q", tt.expectText, text)
+			}
+			if len(elicitor.forms) != 1 || elicitor.forms[0].Message != "The customer has no email address" {
+				t.Fatalf("unexpected forms: %+v", elicitor.forms)
+			}
+			var fields []string
+			for _, field := range elicitor.forms[0].Fields {
+				fields = append(fields, fmt.Sprintf("%s:%s:%v", field.Name, field.Type, field.Required))
+			}
+			if expected := "email:string:true plan:string:false seats:integer:true"; strings.Join(fields, " ") != expected {
+				t.Errorf("expected fields %q but got %q", expected, strings.Join(fields, " "))
+			}
+			if email, seats := elicitor.forms[0].Fields[0], elicitor.forms[0].Fields[2]; email.Format != "email" ||
+				seats.Minimum == nil || *seats.Minimum != 1 {
+				t.Errorf("expected the field constraints to be kept but got %+v", elicitor.forms[0].Fields)
+			}
+		})
+	}
+}
+
+func TestConvertRequestedSchema(t *testing.T) {
+	tests := []struct {
+		name         string
+		schema       string
+		expectFields []string
+		expectError  string
+	}{
+		{
+			name: "fields are ordered by name",
+			schema: `{"type": "object", "properties": {
+				"name": {"type": "string"},
+				"plan": {"type": "string", "enum": ["free", "pro"]},
+				"seats": {"type": "integer", "minimum": 1},
+				"newsletter": {"type": "boolean", "default": false}
+			}}`,
+			expectFields: []string{"name", "newsletter", "plan", "seats"},
+		},
+		{
+			name:        "nested objects cannot be shown",
+			schema:      `{"type": "object", "properties": {"address": {"type": "object"}}}`,
+			expectError: `property address has unsupported type "object"`,
+		},
+		{
+			name:        "not an object",
+			schema:      `{"type": "string"}`,
+			expectError: `requested schema must be an object, got "string"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form, err := convertRequestedSchema("Details please", json.RawMessage(tt.schema))
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("expected error %q but got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			var names []string
+			for _, field := range form.Fields {
+				names = append(names, field.Name)
+			}
+			if len(names) != len(tt.expectFields) {
+				t.Fatalf("expected fields %v but got %v", tt.expectFields, names)
+			}
+			for i := range names {
+				if names[i] != tt.expectFields[i] {
+					t.Errorf("expected fields %v but got %v", tt.expectFields, names)
+				}
+			}
+		})
+	}
+}
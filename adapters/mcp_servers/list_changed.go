@@ -0,0 +1,143 @@
+package mcp_servers
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// refreshTimeout bounds fetching the lists of a server again
+const refreshTimeout = 30 * time.Second
+
+// serverLists is a set of the lists a server exposes
+type serverLists uint8
+
+const (
+	toolsList serverLists = 1 << iota
+	resourcesList
+	promptsList
+
+	allLists = toolsList | resourcesList | promptsList
+)
+
+// changedLists maps list_changed notifications to the list they announce
+var changedLists = map[string]serverLists{
+	mcp.MethodNotificationToolsListChanged:     toolsList,
+	mcp.MethodNotificationResourcesListChanged: resourcesList,
+	mcp.MethodNotificationPromptsListChanged:   promptsList,
+}
+
+// ListChange reports the tools, resources or prompts servers added or removed
+type ListChange struct {
+	// Kind is "tools", "resources" or "prompts"
+	Kind    string
+	Added   []string
+	Removed []string
+}
+
+// OnListChanged sets the handler told about the changes found by Refresh.
+// Without one they are logged.
+func (r *Registry) OnListChanged(handler func(change ListChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onListChanged = handler
+}
+
+// markStale records that lists of the server changed. They are fetched again
+// by the next Refresh, since notifications arrive on the goroutine that reads
+// the server's responses and cannot wait for a request themselves.
+func (s *Server) markStale(lists serverLists) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stale |= lists
+}
+
+// takeStale returns the lists marked stale and clears the marks
+func (s *Server) takeStale() serverLists {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stale := s.stale
+	s.stale = 0
+	return stale
+}
+
+// Refresh fetches the lists servers announced as changed and rebuilds the
+// catalog, reporting which tools, resources and prompts were added or removed
+func (r *Registry) Refresh() {
+	refreshed := false
+	for _, server := range r.servers {
+		stale := server.takeStale()
+		if stale == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		r.fetchLists(ctx, server, stale)
+		cancel()
+		refreshed = true
+	}
+	if !refreshed {
+		return
+	}
+
+	r.mu.Lock()
+	before := r.catalogNames()
+	r.buildCatalog()
+	after := r.catalogNames()
+	handler := r.onListChanged
+	r.mu.Unlock()
+
+	for _, kind := range []string{"tools", "resources", "prompts"} {
+		added, removed := diffNames(before[kind], after[kind])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		if handler != nil {
+			handler(ListChange{Kind: kind, Added: added, Removed: removed})
+			continue
+		}
+		if len(added) > 0 {
+			log.Printf("Added %s: %s", kind, strings.Join(added, ", "))
+		}
+		if len(removed) > 0 {
+			log.Printf("Removed %s: %s", kind, strings.Join(removed, ", "))
+		}
+	}
+}
+
+// catalogNames returns the names in the catalog by kind. The caller holds the lock.
+func (r *Registry) catalogNames() map[string][]string {
+	names := map[string][]string{"tools": nil, "resources": nil, "prompts": nil}
+	for _, tool := range r.tools {
+		names["tools"] = append(names["tools"], tool.Name)
+	}
+	for _, resource := range r.resources {
+		names["resources"] = append(names["resources"], resource.Name)
+	}
+	for _, template := range r.templates {
+		names["resources"] = append(names["resources"], template.Name)
+	}
+	for _, prompt := range r.prompts {
+		names["prompts"] = append(names["prompts"], prompt.Name)
+	}
+	return names
+}
+
+// diffNames returns the names only in after and the names only in before
+func diffNames(before, after []string) ([]string, []string) {
+	var added, removed []string
+	for _, name := range after {
+		if !slices.Contains(before, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,114 @@
+package mcp_servers
+
+import (
+	"context"
+	"mcp_client/core/domain"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRegistry_RefreshesChangedLists(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport string
+		serve     func(mcpServer *server.MCPServer) (*httptest.Server, string)
+	}{
+		{
+			name:      "sse",
+			transport: domain.TransportSSE,
+			serve: func(mcpServer *server.MCPServer) (*httptest.Server, string) {
+				httpServer := server.NewTestServer(mcpServer)
+				return httpServer, httpServer.URL + "/sse"
+			},
+		},
+		{
+			// Notifications sent while no request is in flight need the standing GET stream
+			name:      "streamable http",
+			transport: domain.TransportStreamableHTTP,
+			serve: func(mcpServer *server.MCPServer) (*httptest.Server, string) {
+				httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+				return httpServer, httpServer.URL + "/mcp"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpServer := server.NewMCPServer("crm", "1.0.0",
+				server.WithToolCapabilities(true),
+				server.WithPromptCapabilities(true))
+			noop := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ok"), nil
+			}
+			mcpServer.AddTool(mcp.NewTool("lookup"), noop)
+			// The in-process transport does not deliver notifications, the HTTP transports do
+			httpServer, url := tt.serve(mcpServer)
+			defer httpServer.Close()
+			registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: url, Transport: tt.transport}},
+				NewDialer().Connect)
+			if err := registry.Start(context.Background()); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			defer registry.Close()
+			var mu sync.Mutex
+			var changes []ListChange
+			registry.OnListChanged(func(change ListChange) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, change)
+			})
+			// Give the client time to open its listening stream
+			time.Sleep(100 * time.Millisecond)
+
+			// An admin logs in and the server enables its admin tools
+			mcpServer.AddTool(mcp.NewTool("admin_reset"), noop)
+			mcpServer.DeleteTools("lookup")
+			mcpServer.AddPrompt(mcp.NewPrompt("audit"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return mcp.NewGetPromptResult("Audit", nil), nil
+			})
+
+			// Notifications are delivered asynchronously
+			deadline := time.Now().Add(2 * time.Second)
+			var names []string
+			for time.Now().Before(deadline) {
+				registry.Refresh()
+				names = nil
+				for _, tool := range registry.ToolParams() {
+					names = append(names, tool.OfTool.Name)
+				}
+				if len(names) == 1 && names[0] == "crm__admin_reset" && len(registry.Prompts()) == 1 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if len(names) != 1 || names[0] != "crm__admin_reset" {
+				t.Errorf("expected only the admin tool but got %v", names)
+			}
+			if prompts := registry.Prompts(); len(prompts) != 1 || prompts[0].Name != "crm__audit" {
+				t.Errorf("expected the new prompt but got %+v", prompts)
+			}
+			if result := registry.CallTool(context.Background(), "crm__lookup", []byte(`{}`)); !result.IsError.Value {
+				t.Errorf("expected the removed tool to be gone")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			var added, removed []string
+			for _, change := range changes {
+				added = append(added, change.Added...)
+				removed = append(removed, change.Removed...)
+			}
+			slices.Sort(added)
+			if !slices.Equal(added, []string{"crm__admin_reset", "crm__audit"}) || !slices.Equal(removed, []string{"crm__lookup"}) {
+				t.Errorf("expected the changes to be reported but got %+v", changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rawRequestID numbers the requests sent past the client. String IDs cannot
+// collide with the numeric IDs the client uses itself.
+var rawRequestID atomic.Int64
+
+// listToolsPage lists one page of the tools of a server. Unlike
+// client.ListToolsByPage it keeps each input schema exactly as the server sent
+// it in RawInputSchema; mcp.ToolInputSchema only holds type, properties and required.
+func listToolsPage(ctx context.Context, mcpClient *client.Client, cursor mcp.Cursor) ([]mcp.Tool, mcp.Cursor, error) {
+	result, err := sendRaw(ctx, mcpClient, "tools/list", mcp.PaginatedParams{Cursor: cursor})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Tools      []json.RawMessage `json:"tools"`
+		NextCursor mcp.Cursor        `json:"nextCursor"`
+	}
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	tools := make([]mcp.Tool, 0, len(page.Tools))
+	for _, raw := range page.Tools {
+		tool, err := parseTool(raw)
+		if err != nil {
+			return nil, "", err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, page.NextCursor, nil
+}
+
+func parseTool(raw json.RawMessage) (mcp.Tool, error) {
+	var tool mcp.Tool
+	if err := json.Unmarshal(raw, &tool); err != nil {
+		return tool, fmt.Errorf("failed to unmarshal tool: %w", err)
+	}
+	var schema struct {
+		InputSchema json.RawMessage `json:"inputSchema"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return tool, fmt.Errorf("failed to unmarshal tool %s: %w", tool.Name, err)
+	}
+	if len(schema.InputSchema) > 0 {
+		// A tool has either schema, never both
+		tool.InputSchema = mcp.ToolInputSchema{}
+		tool.RawInputSchema = schema.InputSchema
+	}
+	return tool, nil
+}
+
+// newRequestID returns an ID for a request sent past the client
+func newRequestID() mcp.RequestId {
+	return mcp.NewRequestId(fmt.Sprintf("mcp_client-%d", rawRequestID.Add(1)))
+}
+
+// sendRaw sends a request through the transport of the client and returns the
+// raw result. Errors are reported the way the client reports them.
+func sendRaw(ctx context.Context, mcpClient *client.Client, method string, params any) (json.RawMessage, error) {
+	return sendRawWithID(ctx, mcpClient, newRequestID(), method, params)
+}
+
+// sendRawWithID is sendRaw for requests whose ID the caller needs, e.g. to cancel them
+func sendRawWithID(ctx context.Context, mcpClient *client.Client, id mcp.RequestId, method string, params any) (json.RawMessage, error) {
+	response, err := mcpClient.GetTransport().SendRequest(ctx, transport.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, transport.NewError(err)
+	}
+	if response.Error != nil {
+		return nil, response.Error.AsError()
+	}
+	return response.Result, nil
+}
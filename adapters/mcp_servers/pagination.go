@@ -0,0 +1,75 @@
+package mcp_servers
+
+import (
+	"context"
+	"log"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultMaxListPages limits the pages fetched of each list when the config does not
+const defaultMaxListPages = 20
+
+// maxListPages returns how many pages of each list are fetched from the server
+func (s *Server) maxListPages() int {
+	if s.Config.MaxListPages > 0 {
+		return s.Config.MaxListPages
+	}
+	return defaultMaxListPages
+}
+
+// listPages follows the cursors of a list until the server has no more pages
+// or the page limit of the server is reached. It returns the items and the
+// cursor of the first page left out, which is empty when the list is complete.
+func listPages[T any](ctx context.Context, server *Server, kind string,
+	fetch func(ctx context.Context, mcpClient *client.Client, cursor mcp.Cursor) ([]T, mcp.Cursor, error),
+) ([]T, mcp.Cursor, error) {
+	mcpClient := server.Client()
+	var items []T
+	var cursor mcp.Cursor
+	for range server.maxListPages() {
+		page, next, err := fetch(ctx, mcpClient, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, page...)
+		if next == "" {
+			return items, "", nil
+		}
+		cursor = next
+	}
+	log.Printf("Server %s has more than %d pages of %s, only the first %d %s are listed",
+		server.Config.Name, server.maxListPages(), kind, len(items), kind)
+	return items, cursor, nil
+}
+
+func listResourcesPage(ctx context.Context, mcpClient *client.Client, cursor mcp.Cursor) ([]mcp.Resource, mcp.Cursor, error) {
+	request := mcp.ListResourcesRequest{}
+	request.Params.Cursor = cursor
+	result, err := mcpClient.ListResourcesByPage(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	return result.Resources, result.NextCursor, nil
+}
+
+func listResourceTemplatesPage(ctx context.Context, mcpClient *client.Client, cursor mcp.Cursor) ([]mcp.ResourceTemplate, mcp.Cursor, error) {
+	request := mcp.ListResourceTemplatesRequest{}
+	request.Params.Cursor = cursor
+	result, err := mcpClient.ListResourceTemplatesByPage(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	return result.ResourceTemplates, result.NextCursor, nil
+}
+
+func listPromptsPage(ctx context.Context, mcpClient *client.Client, cursor mcp.Cursor) ([]mcp.Prompt, mcp.Cursor, error) {
+	request := mcp.ListPromptsRequest{}
+	request.Params.Cursor = cursor
+	result, err := mcpClient.ListPromptsByPage(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	return result.Prompts, result.NextCursor, nil
+}
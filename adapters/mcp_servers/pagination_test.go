@@ -0,0 +1,113 @@
+package mcp_servers
+
+import (
+	"context"
+	"fmt"
+	"mcp_client/core/domain"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newCustomerServer creates a server listing count customers as resources, two per page
+func newCustomerServer(count int) *server.MCPServer {
+	mcpServer := server.NewMCPServer("crm", "1.0.0", server.WithResourceCapabilities(false, false), server.WithPaginationLimit(2))
+	for i := range count {
+		uri := fmt.Sprintf("customers://%d", i)
+		mcpServer.AddResource(mcp.NewResource(uri, fmt.Sprintf("customer_%d", i)), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, Text: "Customer " + uri}}, nil
+		})
+	}
+	return mcpServer
+}
+
+func TestRegistry_FollowsListPages(t *testing.T) {
+	tests := []struct {
+		name            string
+		count           int
+		maxListPages    int
+		expectResources int
+		expectList      bool
+		expectTools     string
+	}{
+		{
+			name: "all pages", count: 5, expectResources: 5,
+			expectTools: "crm__customer_0,crm__customer_1,crm__customer_2,crm__customer_3,crm__customer_4",
+		},
+		{name: "page limit", count: 5, maxListPages: 2, expectResources: 4, expectList: true, expectTools: "crm__list_resources"},
+		{name: "too many resources for a tool each", count: 12, expectResources: 12, expectList: true, expectTools: "crm__list_resources"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connect := inProcessConnector(map[string]*server.MCPServer{"crm": newCustomerServer(tt.count)})
+			registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process", MaxListPages: tt.maxListPages}}, connect)
+			if err := registry.Start(context.Background()); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			defer registry.Close()
+
+			if resources := registry.Resources(); len(resources) != tt.expectResources {
+				t.Errorf("expected %d resources but got %d", tt.expectResources, len(resources))
+			}
+			if lists := registry.ResourceLists(); (len(lists) == 1) != tt.expectList {
+				t.Errorf("expected a resource list tool: %v, but got %+v", tt.expectList, lists)
+			}
+			var names []string
+			for _, param := range registry.ToolParams() {
+				names = append(names, param.OfTool.Name)
+			}
+			if tools := strings.Join(names, ","); tools != tt.expectTools {
+				t.Errorf("expected tools %q but got %q", tt.expectTools, tools)
+			}
+			// Resources without a tool of their own are not callable by name
+			if result := registry.CallTool(context.Background(), "crm__customer_0", []byte(`{}`)); result.IsError.Value != tt.expectList {
+				t.Errorf("expected the resource tool to be callable: %v, but got %+v", !tt.expectList, result.Content)
+			}
+		})
+	}
+}
+
+func TestRegistry_ResourceListPagesThroughResources(t *testing.T) {
+	connect := inProcessConnector(map[string]*server.MCPServer{"crm": newCustomerServer(5)})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process", MaxListPages: 1}}, connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	cursorPattern := regexp.MustCompile(`cursor "([^"]+)"`)
+	var uris []string
+	input := `{}`
+	for range 5 {
+		result := registry.CallTool(context.Background(), "crm__list_resources", []byte(input))
+		if result.IsError.Value {
+			t.Fatalf("expected no error but got: %s", result.Content[0].OfText.Text)
+		}
+		text := result.Content[0].OfText.Text
+		for _, line := range strings.Split(text, "\n") {
+			if uri, _, ok := strings.Cut(line, " - "); ok {
+				uris = append(uris, uri)
+			}
+		}
+		match := cursorPattern.FindStringSubmatch(text)
+		if match == nil {
+			break
+		}
+		input = fmt.Sprintf(`{"cursor": %q}`, match[1])
+	}
+	if len(uris) != 5 {
+		t.Errorf("expected all 5 resources but got %v", uris)
+	}
+
+	result := registry.CallTool(context.Background(), "crm__list_resources", []byte(`{"uri": "customers://4"}`))
+	if result.IsError.Value || !strings.Contains(result.Content[0].OfText.Text, "Customer customers://4") {
+		t.Errorf("expected the resource to be read but got %+v", result.Content)
+	}
+	if result := registry.CallTool(context.Background(), "crm__list_resources", []byte(`{"page": 2}`)); !result.IsError.Value {
+		t.Errorf("expected invalid input to be rejected")
+	}
+}
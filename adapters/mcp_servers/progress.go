@@ -0,0 +1,136 @@
+package mcp_servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	progressNotification  = "notifications/progress"
+	cancelledNotification = "notifications/cancelled"
+	// cancelTimeout bounds telling a server that a call was cancelled
+	cancelTimeout = 5 * time.Second
+)
+
+// errCancelledByUser is the cause of calls cancelled with CancelCalls
+var errCancelledByUser = errors.New("the user cancelled the call, it may or may not have taken effect")
+
+// ToolProgress reports the progress of a running tool call
+type ToolProgress struct {
+	Tool     string
+	Progress float64
+	// Total is zero when the server does not know how much there is to do
+	Total   float64
+	Message string
+	// Done marks the end of a call that reported progress
+	Done bool
+}
+
+// toolCall is a tool call in flight, keyed by its progress token
+type toolCall struct {
+	name       string
+	cancel     context.CancelCauseFunc
+	progressed bool
+}
+
+// OnProgress sets the handler receiving the progress of running tool calls
+func (r *Registry) OnProgress(handler func(progress ToolProgress)) {
+	r.callsMu.Lock()
+	defer r.callsMu.Unlock()
+	r.onProgress = handler
+}
+
+// CancelCalls cancels the tool calls in flight and returns how many there were.
+// The servers are told, and the calls report the cancellation as their error.
+func (r *Registry) CancelCalls() int {
+	r.callsMu.Lock()
+	defer r.callsMu.Unlock()
+	for _, call := range r.calls {
+		call.cancel(errCancelledByUser)
+	}
+	return len(r.calls)
+}
+
+// callTool calls a tool asking for progress notifications. A call that is
+// cancelled or times out is cancelled on the server too.
+func (r *Registry) callTool(ctx context.Context, entry CatalogTool, arguments map[string]any) (*mcp.CallToolResult, error) {
+	id := newRequestID()
+	token := id.String()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	call := &toolCall{name: entry.Name, cancel: cancel}
+	r.callsMu.Lock()
+	if r.calls == nil {
+		r.calls = make(map[string]*toolCall)
+	}
+	r.calls[token] = call
+	r.callsMu.Unlock()
+	defer func() {
+		r.callsMu.Lock()
+		delete(r.calls, token)
+		progressed, handler := call.progressed, r.onProgress
+		r.callsMu.Unlock()
+		if progressed && handler != nil {
+			handler(ToolProgress{Tool: entry.Name, Done: true})
+		}
+	}()
+
+	params := mcp.CallToolParams{
+		Name:      entry.Tool.Name,
+		Arguments: arguments,
+		Meta:      &mcp.Meta{ProgressToken: token},
+	}
+	result, err := sendRawWithID(ctx, entry.Server.Client(), id, "tools/call", params)
+	if err != nil {
+		if ctx.Err() == nil {
+			return nil, err
+		}
+		cancelRequest(entry.Server, id, context.Cause(ctx))
+		if cause := context.Cause(ctx); errors.Is(cause, errCancelledByUser) {
+			return nil, cause
+		}
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(&result)
+}
+
+// progressed passes a progress notification on to the handler
+func (r *Registry) progressed(params mcp.NotificationParams) {
+	token, _ := params.AdditionalFields["progressToken"].(string)
+	r.callsMu.Lock()
+	call, ok := r.calls[token]
+	if ok {
+		call.progressed = true
+	}
+	handler := r.onProgress
+	r.callsMu.Unlock()
+	if !ok || handler == nil {
+		return
+	}
+
+	progress := ToolProgress{Tool: call.name}
+	progress.Progress, _ = params.AdditionalFields["progress"].(float64)
+	progress.Total, _ = params.AdditionalFields["total"].(float64)
+	progress.Message, _ = params.AdditionalFields["message"].(string)
+	handler(progress)
+}
+
+// cancelRequest tells the server to stop working on a request the client gave up on
+func cancelRequest(server *Server, id mcp.RequestId, reason error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+	notification.Method = cancelledNotification
+	notification.Params.AdditionalFields = map[string]any{
+		"requestId": id.Value(),
+		"reason":    reason.Error(),
+	}
+	if err := server.Client().GetTransport().SendNotification(ctx, notification); err != nil {
+		log.Printf("Failed to tell server %s about the cancelled request %s: %v", server.Config.Name, id.String(), err)
+	}
+}
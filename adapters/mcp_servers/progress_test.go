@@ -0,0 +1,77 @@
+package mcp_servers
+
+import (
+	"context"
+	"mcp_client/core/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRegistry_ReportsProgressAndCancelsCalls(t *testing.T) {
+	mcpServer := server.NewMCPServer("crm", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("import"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		err := server.ServerFromContext(ctx).SendNotificationToClient(ctx, progressNotification, map[string]any{
+			"progressToken": request.Params.Meta.ProgressToken,
+			"progress":      250,
+			"total":         1000,
+			"message":       "Importing customers",
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The import runs until it is cancelled
+		<-ctx.Done()
+		return mcp.NewToolResultText("done"), nil
+	})
+	cancelled := make(chan any, 1)
+	mcpServer.AddNotificationHandler(cancelledNotification, func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		cancelled <- notification.Params.AdditionalFields["requestId"]
+	})
+	// The in-process transport does not deliver notifications, the SSE transport does
+	sseServer := server.NewTestServer(mcpServer)
+	defer sseServer.Close()
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: sseServer.URL + "/sse", Transport: domain.TransportSSE}},
+		NewDialer().Connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	progress := make(chan ToolProgress, 2)
+	registry.OnProgress(func(update ToolProgress) {
+		progress <- update
+		if !update.Done {
+			// The user presses Ctrl-C after seeing the progress
+			registry.CancelCalls()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result := registry.CallTool(ctx, "crm__import", []byte(`{}`))
+
+	if !result.IsError.Value || !strings.Contains(result.Content[0].OfText.Text, "the user cancelled the call") {
+		t.Errorf("expected the cancellation as error result but got %+v", result.Content[0].OfText)
+	}
+	if update := <-progress; update.Tool != "crm__import" || update.Progress != 250 || update.Total != 1000 || update.Message != "Importing customers" {
+		t.Errorf("expected the progress of the import but got %+v", update)
+	}
+	if update := <-progress; !update.Done {
+		t.Errorf("expected the end of the call to be reported but got %+v", update)
+	}
+	select {
+	case id := <-cancelled:
+		if id == nil {
+			t.Errorf("expected the cancellation to name the request")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected the server to be told about the cancellation")
+	}
+	if calls := registry.CancelCalls(); calls != 0 {
+		t.Errorf("expected no calls in flight but got %d", calls)
+	}
+}
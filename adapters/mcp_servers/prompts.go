@@ -0,0 +1,159 @@
+package mcp_servers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CatalogPrompt is a server prompt under its namespaced name
+type CatalogPrompt struct {
+	Name   string
+	Server *Server
+	Prompt mcp.Prompt
+}
+
+// Usage describes how to invoke the prompt, e.g. "crm__onboard name=<name> [plan=<plan>]"
+func (p CatalogPrompt) Usage() string {
+	usage := []string{p.Name}
+	for _, argument := range p.Prompt.Arguments {
+		if argument.Required {
+			usage = append(usage, fmt.Sprintf("%s=<%s>", argument.Name, argument.Name))
+		} else {
+			usage = append(usage, fmt.Sprintf("[%s=<%s>]", argument.Name, argument.Name))
+		}
+	}
+	return strings.Join(usage, " ")
+}
+
+// Prompts returns the merged prompts of all servers, fetching changed lists first
+func (r *Registry) Prompts() []CatalogPrompt {
+	r.Refresh()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.prompts
+}
+
+// FindPrompt looks a prompt up by its namespaced name, or by its own name when only one server has it
+func (r *Registry) FindPrompt(name string) (CatalogPrompt, error) {
+	var matches []CatalogPrompt
+	for _, prompt := range r.Prompts() {
+		if prompt.Name == name {
+			return prompt, nil
+		}
+		if prompt.Prompt.Name == name {
+			matches = append(matches, prompt)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return CatalogPrompt{}, fmt.Errorf("prompt not found: %s", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, match := range matches {
+			names[i] = match.Name
+		}
+		return CatalogPrompt{}, fmt.Errorf("prompt %s is offered by several servers, use one of %s", name, strings.Join(names, ", "))
+	}
+}
+
+// GetPrompt fetches a prompt with the given arguments and returns its
+// messages ready to be added to a conversation. The arguments are checked
+// against the ones the prompt declares before the server is asked.
+func (r *Registry) GetPrompt(ctx context.Context, name string, arguments map[string]string) ([]anthropic.MessageParam, error) {
+	prompt, err := r.FindPrompt(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPromptArguments(prompt, arguments); err != nil {
+		return nil, err
+	}
+
+	result, err := prompt.Server.Client().GetPrompt(ctx, mcp.GetPromptRequest{
+		Params: mcp.GetPromptParams{
+			Name:      prompt.Prompt.Name,
+			Arguments: arguments,
+		},
+	})
+	if err != nil {
+		return nil, callError(prompt.Server, err)
+	}
+	return convertPromptMessages(result.Messages), nil
+}
+
+// checkPromptArguments reports unknown and missing arguments together with the usage of the prompt
+func checkPromptArguments(prompt CatalogPrompt, arguments map[string]string) error {
+	declared := make(map[string]bool, len(prompt.Prompt.Arguments))
+	var problems []string
+	for _, argument := range prompt.Prompt.Arguments {
+		declared[argument.Name] = true
+		if _, ok := arguments[argument.Name]; argument.Required && !ok {
+			problems = append(problems, "missing argument "+argument.Name)
+		}
+	}
+	var unknown []string
+	for name := range arguments {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, "unknown argument "+name)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	usage := "usage: " + prompt.Usage()
+	for _, argument := range prompt.Prompt.Arguments {
+		if argument.Description != "" {
+			usage += fmt.Sprintf("\n  %s: %s", argument.Name, argument.Description)
+		}
+	}
+	return fmt.Errorf("%s\n%s", strings.Join(problems, ", "), usage)
+}
+
+// convertPromptMessages translates prompt messages with the same content
+// conversion as tool results
+func convertPromptMessages(promptMessages []mcp.PromptMessage) []anthropic.MessageParam {
+	var messages []anthropic.MessageParam
+	for _, promptMessage := range promptMessages {
+		if message, ok := convertMessage(promptMessage.Role, promptMessage.Content); ok {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// convertMessage translates a message of a prompt or sampling request. It
+// reports false for content without anything to send, such as empty text.
+func convertMessage(role mcp.Role, content mcp.Content) (anthropic.MessageParam, bool) {
+	block, ok := convertContent(content)
+	if !ok {
+		return anthropic.MessageParam{}, false
+	}
+
+	var messageContent anthropic.ContentBlockParamUnion
+	if block.OfImage != nil {
+		messageContent = anthropic.ContentBlockParamUnion{OfImage: block.OfImage}
+	} else {
+		messageContent = anthropic.NewTextBlock(block.OfText.Text)
+	}
+
+	messageRole := anthropic.MessageParamRoleUser
+	if role == mcp.RoleAssistant {
+		messageRole = anthropic.MessageParamRoleAssistant
+	}
+	return anthropic.MessageParam{
+		Role:    messageRole,
+		Content: []anthropic.ContentBlockParamUnion{messageContent},
+	}, true
+}
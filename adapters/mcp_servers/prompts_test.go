@@ -0,0 +1,85 @@
+package mcp_servers
+
+import (
+	"context"
+	"mcp_client/core/domain"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRegistry_GetPrompt(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithPromptCapabilities(false))
+	mcpServer.AddPrompt(mcp.NewPrompt("onboard",
+		mcp.WithPromptDescription("Onboard a new customer"),
+		mcp.WithArgument("name", mcp.RequiredArgument(), mcp.ArgumentDescription("Customer name")),
+		mcp.WithArgument("plan"),
+	), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("Onboarding", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("Onboard "+request.Params.Arguments["name"])),
+			mcp.NewPromptMessage(mcp.RoleAssistant, mcp.NewTextContent("Which plan?")),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewImageContent("iVBORw0KGgo=", "image/png")),
+		}), nil
+	})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process"}},
+		inProcessConnector(map[string]*server.MCPServer{"crm": mcpServer}))
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	tests := []struct {
+		name        string
+		prompt      string
+		arguments   map[string]string
+		expectError string
+	}{
+		{
+			name:      "namespaced name",
+			prompt:    "crm__onboard",
+			arguments: map[string]string{"name": "Ada"},
+		},
+		{
+			name:      "name offered by one server only",
+			prompt:    "onboard",
+			arguments: map[string]string{"name": "Ada", "plan": "pro"},
+		},
+		{
+			name:        "missing and unknown arguments",
+			prompt:      "onboard",
+			arguments:   map[string]string{"email": "ada@example.com"},
+			expectError: "missing argument name, unknown argument email\nusage: crm__onboard name=<name> [plan=<plan>]\n  name: Customer name",
+		},
+		{
+			name:        "unknown prompt",
+			prompt:      "offboard",
+			expectError: "prompt not found: offboard",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := registry.GetPrompt(context.Background(), tt.prompt, tt.arguments)
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("expected error %q but got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if len(messages) != 3 || messages[0].Content[0].OfText.Text != "Onboard Ada" ||
+				messages[1].Role != "assistant" || messages[2].Content[0].OfImage == nil {
+				t.Errorf("unexpected messages: %+v", messages)
+			}
+		})
+	}
+
+	if usage := registry.Prompts()[0].Usage(); !strings.HasPrefix(usage, "crm__onboard") {
+		t.Errorf("unexpected usage %q", usage)
+	}
+}
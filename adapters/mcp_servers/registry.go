@@ -0,0 +1,491 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mcp_client/core/domain"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// maxToolNameLength is the longest tool name the Anthropic API accepts
+const maxToolNameLength = 64
+
+// defaultMaxConcurrency limits concurrent tool calls per server when the config does not
+const defaultMaxConcurrency = 4
+
+// Connector creates, starts and initializes an MCP client for a server
+type Connector func(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error)
+
+// Server is a connected MCP server together with what it exposes
+type Server struct {
+	Config    domain.ServerConfig
+	Info      *mcp.InitializeResult
+	Tools     []mcp.Tool
+	Resources []mcp.Resource
+	// ResourceTemplates are resources addressed by URI templates such as customers://{id}
+	ResourceTemplates []mcp.ResourceTemplate
+	Prompts           []mcp.Prompt
+
+	// moreResources tells that Resources stops at the page limit and the server has more
+	moreResources bool
+
+	mu      sync.RWMutex
+	client  *client.Client
+	closing bool
+	// restarting is set while a crashed server is being restarted
+	restarting bool
+	// stale marks the lists the server announced as changed since they were fetched
+	stale serverLists
+
+	// slots holds a token for every tool call running on the server
+	slots chan struct{}
+}
+
+// acquire waits until the server may take another tool call. The returned func releases the slot.
+func (s *Server) acquire(ctx context.Context) (func(), error) {
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free slot on server %s: %w", s.Config.Name, ctx.Err())
+	}
+}
+
+// Client returns the client currently connected to the server.
+// It changes when a crashed stdio server is restarted.
+func (s *Server) Client() *client.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// CatalogTool is a server tool under its namespaced name
+type CatalogTool struct {
+	Name   string
+	Server *Server
+	Tool   mcp.Tool
+	// InputSchema is the input schema of the tool as sent to the Anthropic API
+	InputSchema map[string]any
+
+	// validator checks inputs before they are sent to the server
+	validator *jsonschema.Schema
+}
+
+// CatalogResource is a server resource under its namespaced name
+type CatalogResource struct {
+	Name     string
+	Server   *Server
+	Resource mcp.Resource
+}
+
+// CatalogResourceTemplate is a server resource template under its namespaced
+// name. It is exposed as a tool taking the template variables as input.
+type CatalogResourceTemplate struct {
+	Name        string
+	Server      *Server
+	Template    mcp.ResourceTemplate
+	InputSchema map[string]any
+
+	validator *jsonschema.Schema
+}
+
+// Registry connects to several MCP servers and merges what they expose into one catalog
+type Registry struct {
+	configs []domain.ServerConfig
+	connect Connector
+	servers []*Server
+	// restartBackoff is the delay before the first restart of a crashed server
+	restartBackoff time.Duration
+	// exitCheckTimeout bounds the ping confirming that a server exited
+	exitCheckTimeout time.Duration
+
+	// mu guards the catalog, which is rebuilt when servers change their lists
+	mu        sync.RWMutex
+	tools     []CatalogTool
+	resources []CatalogResource
+	templates []CatalogResourceTemplate
+	prompts   []CatalogPrompt
+	// resourceLists page through the resources of servers with too many to expose each as a tool
+	resourceLists []CatalogResourceList
+	onListChanged func(change ListChange)
+
+	// callsMu guards the tool calls in flight and the progress handler
+	callsMu    sync.Mutex
+	calls      map[string]*toolCall
+	onProgress func(progress ToolProgress)
+
+	// watchMu guards the watched resources and their handler
+	watchMu           sync.Mutex
+	watches           map[string]*watch
+	onResourceUpdated func(update ResourceUpdate)
+}
+
+// NewRegistry creates a registry for the given servers. Nothing is connected until Start is called.
+func NewRegistry(configs []domain.ServerConfig, connect Connector) *Registry {
+	return &Registry{
+		configs:          configs,
+		connect:          connect,
+		restartBackoff:   defaultRestartBackoff,
+		exitCheckTimeout: defaultExitCheckTimeout,
+	}
+}
+
+// Start connects to all servers in parallel and builds the catalog.
+// Servers that fail to connect are logged and left out; an error is returned only if none connect.
+func (r *Registry) Start(ctx context.Context) error {
+	servers := make([]*Server, len(r.configs))
+	var wg sync.WaitGroup
+	for i, config := range r.configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			server, err := r.startServer(ctx, config)
+			if err != nil {
+				log.Printf("Failed to start server %s: %v", config.Name, err)
+				return
+			}
+			servers[i] = server
+		}()
+	}
+	wg.Wait()
+
+	for _, server := range servers {
+		if server != nil {
+			r.servers = append(r.servers, server)
+		}
+	}
+	if len(r.servers) == 0 {
+		return fmt.Errorf("none of the %d configured servers could be started", len(r.configs))
+	}
+
+	r.mu.Lock()
+	r.buildCatalog()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Registry) startServer(ctx context.Context, config domain.ServerConfig) (*Server, error) {
+	mcpClient, serverInfo, err := r.connect(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Connected to server %s: %s (version %s)",
+		config.Name,
+		serverInfo.ServerInfo.Name,
+		serverInfo.ServerInfo.Version)
+
+	maxConcurrency := config.MaxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	server := &Server{
+		Config: config,
+		Info:   serverInfo,
+		slots:  make(chan struct{}, maxConcurrency),
+	}
+	r.attach(server, mcpClient)
+	r.fetchLists(ctx, server, allLists)
+	return server, nil
+}
+
+// fetchLists lists the tools, resources or prompts of a server, as far as it supports them
+func (r *Registry) fetchLists(ctx context.Context, server *Server, lists serverLists) {
+	server.mu.RLock()
+	capabilities := server.Info.Capabilities
+	server.mu.RUnlock()
+
+	if lists&toolsList != 0 && capabilities.Tools != nil {
+		tools, _, err := listPages(ctx, server, "tools", listToolsPage)
+		if err != nil {
+			log.Printf("Failed to list tools of %s: %v", server.Config.Name, err)
+		} else {
+			r.mu.Lock()
+			server.Tools = tools
+			r.mu.Unlock()
+		}
+	}
+
+	if lists&resourcesList != 0 && capabilities.Resources != nil {
+		resources, more, err := listPages(ctx, server, "resources", listResourcesPage)
+		if err != nil {
+			log.Printf("Failed to list resources of %s: %v", server.Config.Name, err)
+		} else {
+			r.mu.Lock()
+			server.Resources = resources
+			server.moreResources = more != ""
+			r.mu.Unlock()
+		}
+
+		templates, _, err := listPages(ctx, server, "resource templates", listResourceTemplatesPage)
+		if err != nil {
+			log.Printf("Failed to list resource templates of %s: %v", server.Config.Name, err)
+		} else {
+			r.mu.Lock()
+			server.ResourceTemplates = templates
+			r.mu.Unlock()
+		}
+	}
+
+	if lists&promptsList != 0 && capabilities.Prompts != nil {
+		prompts, _, err := listPages(ctx, server, "prompts", listPromptsPage)
+		if err != nil {
+			log.Printf("Failed to list prompts of %s: %v", server.Config.Name, err)
+		} else {
+			r.mu.Lock()
+			server.Prompts = prompts
+			r.mu.Unlock()
+		}
+	}
+}
+
+// buildCatalog namespaces the tools and resources of every server, in
+// configuration order. The caller holds the write lock.
+func (r *Registry) buildCatalog() {
+	r.tools = nil
+	r.resources = nil
+	r.templates = nil
+	r.prompts = nil
+	r.resourceLists = nil
+	taken := make(map[string]string)
+	claim := func(name, owner string) bool {
+		if previous, exists := taken[name]; exists {
+			log.Printf("Skipping %s: name %s is already used by %s", owner, name, previous)
+			return false
+		}
+		taken[name] = owner
+		return true
+	}
+
+	for _, server := range r.servers {
+		for _, tool := range server.Tools {
+			name := NamespacedName(server.Config.Name, tool.Name)
+			if !claim(name, server.Config.Name+" tool "+tool.Name) {
+				continue
+			}
+			inputSchema, downgrades := convertInputSchema(tool)
+			for _, downgrade := range downgrades {
+				log.Printf("Tool %s: %s", name, downgrade)
+			}
+			r.tools = append(r.tools, CatalogTool{
+				Name:        name,
+				Server:      server,
+				Tool:        tool,
+				InputSchema: inputSchema,
+				validator:   compileValidator(tool, inputSchema),
+			})
+		}
+		for _, resource := range server.Resources {
+			name := NamespacedName(server.Config.Name, resource.Name)
+			if claim(name, server.Config.Name+" resource "+resource.URI) {
+				r.resources = append(r.resources, CatalogResource{Name: name, Server: server, Resource: resource})
+			}
+		}
+		if server.moreResources || len(server.Resources) > maxResourceTools {
+			name := NamespacedName(server.Config.Name, listResourcesTool)
+			if claim(name, server.Config.Name+" resource list") {
+				validator, err := compileSchema(resourceListInputSchema())
+				if err != nil {
+					log.Printf("Resource list %s: %v", name, err)
+				}
+				r.resourceLists = append(r.resourceLists, CatalogResourceList{Name: name, Server: server, validator: validator})
+			}
+		}
+		for _, template := range server.ResourceTemplates {
+			if template.URITemplate == nil {
+				log.Printf("Skipping %s resource template %s: it has no URI template", server.Config.Name, template.Name)
+				continue
+			}
+			name := NamespacedName(server.Config.Name, template.Name)
+			if !claim(name, server.Config.Name+" resource template "+template.URITemplate.Raw()) {
+				continue
+			}
+			inputSchema := templateInputSchema(template.URITemplate.Raw())
+			validator, err := compileSchema(inputSchema)
+			if err != nil {
+				log.Printf("Resource template %s: %v", name, err)
+			}
+			r.templates = append(r.templates, CatalogResourceTemplate{
+				Name:        name,
+				Server:      server,
+				Template:    template,
+				InputSchema: inputSchema,
+				validator:   validator,
+			})
+		}
+		// Prompts are not tools, so their names do not compete with tool names
+		for _, prompt := range server.Prompts {
+			r.prompts = append(r.prompts, CatalogPrompt{
+				Name:   NamespacedName(server.Config.Name, prompt.Name),
+				Server: server,
+				Prompt: prompt,
+			})
+		}
+	}
+}
+
+// Servers returns the connected servers
+func (r *Registry) Servers() []*Server {
+	return r.servers
+}
+
+// Tools returns the merged tools of all servers
+func (r *Registry) Tools() []CatalogTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tools
+}
+
+// Resources returns the merged resources of all servers
+func (r *Registry) Resources() []CatalogResource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resources
+}
+
+// ResourceTemplates returns the merged resource templates of all servers
+func (r *Registry) ResourceTemplates() []CatalogResourceTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.templates
+}
+
+// CallTool routes a tool_use to the server owning the namespaced name.
+// Resources and resource templates are exposed as tools too, so a name may refer to a resource read.
+// Failures are reported in the result so the model can see them.
+func (r *Registry) CallTool(ctx context.Context, name string, input []byte) anthropic.ToolResultBlockParam {
+	result, err := r.call(ctx, name, input)
+	if err != nil {
+		log.Printf("Error calling tool %s: %v", name, err)
+		return errorToolResult(err.Error())
+	}
+	if result.IsError.Value {
+		log.Printf("Tool %s reported an error", name)
+	}
+	return result
+}
+
+// call returns an error when the call could not be made or answered; errors
+// reported by the tool itself are part of the result.
+func (r *Registry) call(ctx context.Context, name string, input []byte) (anthropic.ToolResultBlockParam, error) {
+	for _, entry := range r.Tools() {
+		if entry.Name != name {
+			continue
+		}
+
+		var arguments map[string]any
+		err := json.Unmarshal(input, &arguments)
+		if err != nil {
+			return anthropic.ToolResultBlockParam{}, fmt.Errorf("the tool input is not a JSON object: %w", err)
+		}
+		// Invalid input is sent back to the model without bothering the server
+		if err := validateInput(entry.Name, entry.validator, input); err != nil {
+			return anthropic.ToolResultBlockParam{}, err
+		}
+		release, err := entry.Server.acquire(ctx)
+		if err != nil {
+			return anthropic.ToolResultBlockParam{}, callError(entry.Server, err)
+		}
+		defer release()
+
+		toolResult, err := r.callTool(ctx, entry, arguments)
+		if err != nil {
+			if errors.As(err, new(*transport.Error)) {
+				// The subprocess of a stdio server may have died with its stderr still open
+				go r.checkExit(entry.Server, entry.Server.Client())
+			}
+			return anthropic.ToolResultBlockParam{}, callError(entry.Server, err)
+		}
+		return convertToolResult(toolResult), nil
+	}
+
+	for _, entry := range r.resourceTools() {
+		if entry.Name == name {
+			return readResource(ctx, entry.Server, entry.Resource.URI)
+		}
+	}
+
+	for _, entry := range r.ResourceLists() {
+		if entry.Name == name {
+			return listResources(ctx, entry, input)
+		}
+	}
+
+	for _, entry := range r.ResourceTemplates() {
+		if entry.Name != name {
+			continue
+		}
+		if err := validateInput(entry.Name, entry.validator, input); err != nil {
+			return anthropic.ToolResultBlockParam{}, err
+		}
+		uri, err := expandTemplate(entry.Template.URITemplate, input)
+		if err != nil {
+			return anthropic.ToolResultBlockParam{}, err
+		}
+		return readResource(ctx, entry.Server, uri)
+	}
+	return anthropic.ToolResultBlockParam{}, fmt.Errorf("tool not found: %s", name)
+}
+
+func readResource(ctx context.Context, server *Server, uri string) (anthropic.ToolResultBlockParam, error) {
+	request := mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{
+			URI: uri,
+		},
+	}
+
+	release, err := server.acquire(ctx)
+	if err != nil {
+		return anthropic.ToolResultBlockParam{}, callError(server, err)
+	}
+	defer release()
+
+	resourceResult, err := server.Client().ReadResource(ctx, request)
+	if err != nil {
+		return anthropic.ToolResultBlockParam{}, callError(server, err)
+	}
+	return convertResourceResult(resourceResult), nil
+}
+
+// Close disconnects from all servers and stops the subprocesses of stdio servers
+func (r *Registry) Close() {
+	for _, server := range r.servers {
+		server.mu.Lock()
+		server.closing = true
+		mcpClient := server.client
+		server.mu.Unlock()
+
+		if err := mcpClient.Close(); err != nil {
+			log.Printf("Failed to close server %s: %v", server.Config.Name, err)
+		}
+	}
+}
+
+// NamespacedName prefixes a name with its server and replaces characters
+// the Anthropic API does not accept in tool names.
+func NamespacedName(serverName, name string) string {
+	namespaced := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, serverName+domain.NamespaceSeparator+name)
+
+	if len(namespaced) > maxToolNameLength {
+		namespaced = namespaced[:maxToolNameLength]
+	}
+	return namespaced
+}
@@ -0,0 +1,263 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/json"
+	"mcp_client/core/domain"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestServer creates an in-process server exposing a "lookup" tool that counts its calls
+func newTestServer(calls *int) *server.MCPServer {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("lookup"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		*calls++
+		return mcp.NewToolResultText("ok"), nil
+	})
+	return mcpServer
+}
+
+// inProcessConnector connects to in-process servers keyed by server name
+func inProcessConnector(servers map[string]*server.MCPServer) Connector {
+	return func(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error) {
+		mcpClient, err := client.NewInProcessClient(servers[config.Name])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := mcpClient.Start(ctx); err != nil {
+			return nil, nil, err
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		serverInfo, err := mcpClient.Initialize(ctx, initRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mcpClient, serverInfo, nil
+	}
+}
+
+func TestRegistry_RoutesNamespacedTools(t *testing.T) {
+	var crmCalls, billingCalls int
+	connect := inProcessConnector(map[string]*server.MCPServer{
+		"crm":     newTestServer(&crmCalls),
+		"billing": newTestServer(&billingCalls),
+	})
+	registry := NewRegistry([]domain.ServerConfig{
+		{Name: "crm", URL: "in-process"},
+		{Name: "billing", URL: "in-process"},
+	}, connect)
+
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	var names []string
+	for _, tool := range registry.Tools() {
+		names = append(names, tool.Name)
+	}
+	if len(names) != 2 || names[0] != "crm__lookup" || names[1] != "billing__lookup" {
+		t.Fatalf("unexpected tool names: %v", names)
+	}
+
+	registry.CallTool(context.Background(), "billing__lookup", []byte(`{}`))
+	if billingCalls != 1 || crmCalls != 0 {
+		t.Errorf("expected the call to reach billing only, got crm=%d billing=%d", crmCalls, billingCalls)
+	}
+
+	result := registry.CallTool(context.Background(), "lookup", []byte(`{}`))
+	if text := result.Content[0].OfText.Text; text != "tool not found: lookup" {
+		t.Errorf("expected a tool not found result for a name without server prefix but got: %s", text)
+	}
+}
+
+func TestRegistry_ToolServer(t *testing.T) {
+	var calls int
+	connect := inProcessConnector(map[string]*server.MCPServer{"crm eu": newTestServer(&calls)})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm eu", URL: "in-process"}}, connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	if server := registry.ToolServer("crm_eu__lookup"); server != "crm eu" {
+		t.Errorf("expected the configured server name but got %q", server)
+	}
+	if server := registry.ToolServer("crm__lookup"); server != "" {
+		t.Errorf("expected no server for an unknown tool but got %q", server)
+	}
+}
+
+func TestNamespacedName(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverName string
+		toolName   string
+		expected   string
+	}{
+		{
+			name:       "plain tool name",
+			serverName: "crm",
+			toolName:   "register_customer",
+			expected:   "crm__register_customer",
+		},
+		{
+			name:       "resource name with spaces",
+			serverName: "crm",
+			toolName:   "List customers",
+			expected:   "crm__List_customers",
+		},
+		{
+			name:       "too long",
+			serverName: "crm",
+			toolName:   "a_very_long_tool_name_that_goes_on_and_on_past_the_api_limit_of_sixty_four",
+			expected:   "crm__a_very_long_tool_name_that_goes_on_and_on_past_the_api_limi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NamespacedName(tt.serverName, tt.toolName)
+			if result != tt.expected {
+				t.Errorf("expected %q but got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRegistry_CallToolErrors(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("insert", mcp.WithString("email", mcp.Required())), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetArguments()["email"] == "ada@example.com" {
+			return mcp.NewToolResultError("duplicate email"), nil
+		}
+		return mcp.NewToolResultText("inserted"), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("removed"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process"}},
+		inProcessConnector(map[string]*server.MCPServer{"crm": mcpServer}))
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+	// The server no longer knows the tool but the catalog still does
+	mcpServer.DeleteTools("removed")
+
+	tests := []struct {
+		name        string
+		tool        string
+		input       string
+		expectError bool
+		expectText  string
+	}{
+		{
+			name:       "success",
+			tool:       "crm__insert",
+			input:      `{"email":"grace@example.com"}`,
+			expectText: "inserted",
+		},
+		{
+			name:        "error reported by the tool",
+			tool:        "crm__insert",
+			input:       `{"email":"ada@example.com"}`,
+			expectError: true,
+			expectText:  "The tool reported an error: duplicate email",
+		},
+		{
+			name:        "call rejected by the server",
+			tool:        "crm__removed",
+			input:       `{}`,
+			expectError: true,
+			expectText:  "server crm rejected the call",
+		},
+		{
+			name:        "input rejected before reaching the server",
+			tool:        "crm__insert",
+			input:       `{"email":42}`,
+			expectError: true,
+			expectText:  "the input does not match the input schema of crm__insert:\n- /email: got number, want string",
+		},
+		{
+			name:        "input that is not an object",
+			tool:        "crm__insert",
+			input:       `[]`,
+			expectError: true,
+			expectText:  "the tool input is not a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := registry.CallTool(context.Background(), tt.tool, []byte(tt.input))
+
+			if result.IsError.Value != tt.expectError {
+				t.Errorf("expected is_error %v but got %v", tt.expectError, result.IsError.Value)
+			}
+			if text := result.Content[0].OfText.Text; !strings.HasPrefix(text, tt.expectText) {
+				t.Errorf("expected text starting with %q but got %q", tt.expectText, text)
+			}
+		})
+	}
+}
+
+func TestRegistry_KeepsRawInputSchemas(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	schema := json.RawMessage(`{"type":"object","additionalProperties":false,"properties":{"address":{"$ref":"#/$defs/address"}},"$defs":{"address":{"type":"string"}}}`)
+	mcpServer.AddTool(mcp.NewToolWithRawSchema("register", "", schema), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process"}},
+		inProcessConnector(map[string]*server.MCPServer{"crm": mcpServer}))
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	inputSchema := registry.Tools()[0].InputSchema
+	address, _ := inputSchema["properties"].(map[string]any)["address"].(map[string]any)
+	if inputSchema["additionalProperties"] != false || address["type"] != "string" {
+		t.Errorf("expected the full schema with the reference inlined but got: %v", inputSchema)
+	}
+}
+
+func TestRegistry_ReadsResourceTemplates(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, false))
+	var readURIs []string
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("customers://{id}{?fields*}", "customer"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			readURIs = append(readURIs, request.Params.URI)
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "Ada"}}, nil
+		})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "crm", URL: "in-process"}},
+		inProcessConnector(map[string]*server.MCPServer{"crm": mcpServer}))
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	templates := registry.ResourceTemplates()
+	if len(templates) != 1 || templates[0].Name != "crm__customer" {
+		t.Fatalf("expected the template as crm__customer but got: %+v", templates)
+	}
+	if required := templates[0].InputSchema["required"]; len(required.([]any)) != 1 {
+		t.Errorf("expected only id to be required but got: %v", required)
+	}
+
+	result := registry.CallTool(context.Background(), "crm__customer", []byte(`{"id":"42","fields":["name","email"]}`))
+	if result.IsError.Value || len(readURIs) != 1 || readURIs[0] != "customers://42?fields=name&fields=email" {
+		t.Errorf("expected a read of the expanded URI but got %v and %+v", readURIs, result)
+	}
+
+	result = registry.CallTool(context.Background(), "crm__customer", []byte(`{}`))
+	if !result.IsError.Value || len(readURIs) != 1 {
+		t.Errorf("expected missing variables to be rejected without a read but got: %+v", result)
+	}
+}
@@ -0,0 +1,137 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// listResourcesTool is the name, before namespacing, of the tool synthesized
+// for a server with too many resources to expose each as a tool
+const listResourcesTool = "list_resources"
+
+// maxResourceTools is how many resources of a server are exposed to Claude as
+// tools of their own. The resources of servers with more are only reachable
+// through their resource list tool, so the tool definitions stay small.
+const maxResourceTools = 10
+
+// CatalogResourceList is a tool synthesized for a server with more resources
+// than maxResourceTools, or more than its page limit lists. It pages through
+// the resources of the server with a cursor, and reads any of them by URI.
+type CatalogResourceList struct {
+	Name   string
+	Server *Server
+
+	validator *jsonschema.Schema
+}
+
+// resourceListInputSchema is the input schema of the resource list tools
+func resourceListInputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"cursor": map[string]any{
+				"type":        "string",
+				"description": "The cursor given with the previous page. Leave it out for the first page.",
+			},
+			"uri": map[string]any{
+				"type":        "string",
+				"description": "The URI of a resource to read instead of listing resources",
+			},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// ResourceLists returns the resource list tools of the servers with too many resources to expose each as a tool
+func (r *Registry) ResourceLists() []CatalogResourceList {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resourceLists
+}
+
+// resourceTools returns the resources exposed to Claude as tools of their
+// own, leaving out those of servers with a resource list tool
+func (r *Registry) resourceTools() []CatalogResource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listed := make(map[*Server]bool, len(r.resourceLists))
+	for _, list := range r.resourceLists {
+		listed[list.Server] = true
+	}
+	var resources []CatalogResource
+	for _, resource := range r.resources {
+		if !listed[resource.Server] {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// listResources answers a call of a resource list tool with a page of
+// resources, or with the content of the resource the input names
+func listResources(ctx context.Context, entry CatalogResourceList, input []byte) (anthropic.ToolResultBlockParam, error) {
+	if err := validateInput(entry.Name, entry.validator, input); err != nil {
+		return anthropic.ToolResultBlockParam{}, err
+	}
+	var arguments struct {
+		Cursor mcp.Cursor `json:"cursor"`
+		URI    string     `json:"uri"`
+	}
+	if err := json.Unmarshal(input, &arguments); err != nil {
+		return anthropic.ToolResultBlockParam{}, fmt.Errorf("the tool input is not a JSON object: %w", err)
+	}
+	if arguments.URI != "" {
+		return readResource(ctx, entry.Server, arguments.URI)
+	}
+
+	release, err := entry.Server.acquire(ctx)
+	if err != nil {
+		return anthropic.ToolResultBlockParam{}, callError(entry.Server, err)
+	}
+	defer release()
+
+	resources, next, err := listResourcesPage(ctx, entry.Server.Client(), arguments.Cursor)
+	if err != nil {
+		return anthropic.ToolResultBlockParam{}, callError(entry.Server, err)
+	}
+	return textToolResult(formatResourcePage(resources, next)), nil
+}
+
+// formatResourcePage lists a page of resources one per line, followed by how to get the next page
+func formatResourcePage(resources []mcp.Resource, next mcp.Cursor) string {
+	var text strings.Builder
+	for _, resource := range resources {
+		fmt.Fprintf(&text, "%s - %s", resource.URI, resource.Name)
+		if resource.Description != "" {
+			fmt.Fprintf(&text, ": %s", resource.Description)
+		}
+		text.WriteByte('\n')
+	}
+	if next != "" {
+		fmt.Fprintf(&text, "More resources follow, pass the cursor %q for the next page.", next)
+	} else {
+		text.WriteString("This is the last page.")
+	}
+	return text.String()
+}
+
+// Convert the resource list tools of the servers to Anthropic tools
+func convertResourceListsToAnthropicTool(lists []CatalogResourceList) []anthropic.ToolUnionParam {
+	anthropicTools := make([]anthropic.ToolParam, len(lists))
+	for i, list := range lists {
+		description := fmt.Sprintf("Lists the resources of the %s server a page at a time, or reads one of them by URI. "+
+			"The resources of this server have no tools of their own.", list.Server.Config.Name)
+		anthropicTools[i] = anthropic.ToolParam{
+			Name:        list.Name,
+			Description: anthropic.String(description),
+			InputSchema: toInputSchemaParam(resourceListInputSchema()),
+		}
+	}
+	return convertToolsToToolUnionParam(anthropicTools)
+}
@@ -0,0 +1,109 @@
+package mcp_servers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// templateExpression matches an RFC 6570 expression such as {id} or {?page,limit}
+var templateExpression = regexp.MustCompile(`\{([+#./;?&]?)([^}]*)\}`)
+
+// templateVariable is a variable of a URI template
+type templateVariable struct {
+	Name string
+	// Optional variables sit in query expressions, which leave them out when undefined
+	Optional bool
+	// Explode variables take a list of values
+	Explode bool
+}
+
+// templateVariables returns the variables of a URI template in order of appearance
+func templateVariables(template string) []templateVariable {
+	var variables []templateVariable
+	seen := make(map[string]bool)
+	for _, expression := range templateExpression.FindAllStringSubmatch(template, -1) {
+		operator := expression[1]
+		for _, spec := range strings.Split(expression[2], ",") {
+			explode := strings.HasSuffix(spec, "*")
+			name, _, _ := strings.Cut(strings.TrimSuffix(spec, "*"), ":")
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			variables = append(variables, templateVariable{
+				Name:     name,
+				Optional: operator == "?" || operator == "&",
+				Explode:  explode,
+			})
+		}
+	}
+	return variables
+}
+
+// templateInputSchema derives the input schema of a resource template tool from the template variables
+func templateInputSchema(template string) map[string]any {
+	properties := map[string]any{}
+	required := []any{}
+	for _, variable := range templateVariables(template) {
+		description := fmt.Sprintf("Value of {%s} in %s", variable.Name, template)
+		if variable.Explode {
+			properties[variable.Name] = map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": description,
+			}
+		} else {
+			properties[variable.Name] = map[string]any{
+				"type":        "string",
+				"description": description,
+			}
+		}
+		if !variable.Optional {
+			required = append(required, variable.Name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// expandTemplate fills the template with the arguments the model gave
+func expandTemplate(template *mcp.URITemplate, input []byte) (string, error) {
+	var arguments map[string]any
+	if err := json.Unmarshal(input, &arguments); err != nil {
+		return "", fmt.Errorf("the tool input is not a JSON object: %w", err)
+	}
+
+	values := uritemplate.Values{}
+	for name, argument := range arguments {
+		switch argument := argument.(type) {
+		case nil:
+		case []any:
+			list := make([]string, len(argument))
+			for i, item := range argument {
+				list[i] = fmt.Sprint(item)
+			}
+			values.Set(name, uritemplate.List(list...))
+		default:
+			values.Set(name, uritemplate.String(fmt.Sprint(argument)))
+		}
+	}
+
+	uri, err := template.Expand(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", template.Raw(), err)
+	}
+	return uri, nil
+}
@@ -0,0 +1,47 @@
+package mcp_servers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplateVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected []templateVariable
+	}{
+		{
+			name:     "simple",
+			template: "customers://{id}",
+			expected: []templateVariable{{Name: "id"}},
+		},
+		{
+			name:     "path and query",
+			template: "crm://{tenant}/customers{/id}{?page,limit}",
+			expected: []templateVariable{
+				{Name: "tenant"},
+				{Name: "id"},
+				{Name: "page", Optional: true},
+				{Name: "limit", Optional: true},
+			},
+		},
+		{
+			name:     "modifiers and repeats",
+			template: "files://{path:3}/{path}{?tags*}",
+			expected: []templateVariable{
+				{Name: "path"},
+				{Name: "tags", Optional: true, Explode: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variables := templateVariables(tt.template)
+			if !reflect.DeepEqual(variables, tt.expected) {
+				t.Errorf("expected %+v but got %+v", tt.expected, variables)
+			}
+		})
+	}
+}
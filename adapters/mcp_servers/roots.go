@@ -0,0 +1,114 @@
+package mcp_servers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Roots are the local directories servers may work in. All servers share
+// them and are told when the user adds or removes one.
+type Roots struct {
+	mu    sync.RWMutex
+	paths []string
+}
+
+var _ client.RootsHandler = (*Roots)(nil)
+
+// NewRoots creates the roots for the given directories
+func NewRoots(paths []string) *Roots {
+	roots := &Roots{}
+	for _, path := range paths {
+		if absolute, err := filepath.Abs(path); err == nil && !slices.Contains(roots.paths, absolute) {
+			roots.paths = append(roots.paths, absolute)
+		}
+	}
+	return roots
+}
+
+// Paths returns the absolute paths of the roots
+func (r *Roots) Paths() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.paths)
+}
+
+// Add makes an existing directory a root and returns its absolute path
+func (r *Roots) Add(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absolute)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", absolute)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.paths, absolute) {
+		return "", fmt.Errorf("%s is already a root", absolute)
+	}
+	r.paths = append(r.paths, absolute)
+	return absolute, nil
+}
+
+// Remove stops a directory from being a root and returns its absolute path
+func (r *Roots) Remove(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.Index(r.paths, absolute)
+	if i < 0 {
+		return "", fmt.Errorf("%s is not a root", absolute)
+	}
+	r.paths = slices.Delete(r.paths, i, i+1)
+	return absolute, nil
+}
+
+// ListRoots answers roots/list
+func (r *Roots) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	result := &mcp.ListRootsResult{Roots: []mcp.Root{}}
+	for _, path := range r.Paths() {
+		result.Roots = append(result.Roots, mcp.Root{
+			URI:  fileURI(path),
+			Name: filepath.Base(path),
+		})
+	}
+	return result, nil
+}
+
+// fileURI turns an absolute path into a file:// URI, e.g. file:///data/csv%20imports
+func fileURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		// Windows paths such as C:/data need a leading slash
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// NotifyRootsChanged tells every server that the roots changed so they can list them again
+func (r *Registry) NotifyRootsChanged(ctx context.Context) {
+	for _, server := range r.servers {
+		if err := server.Client().RootListChanges(ctx); err != nil {
+			log.Printf("Failed to notify %s of the changed roots: %v", server.Config.Name, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package mcp_servers
+
+import (
+	"context"
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRegistry_SharesRoots(t *testing.T) {
+	imports, exports := t.TempDir(), t.TempDir()
+	var changes atomic.Int32
+	mcpServer := server.NewMCPServer("files", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddNotificationHandler(mcp.MethodNotificationRootsListChanged, func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		changes.Add(1)
+	})
+	mcpServer.AddTool(mcp.NewTool("list_roots"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := mcpServer.RequestRoots(ctx, mcp.ListRootsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		var uris []string
+		for _, root := range result.Roots {
+			uris = append(uris, root.URI)
+		}
+		return mcp.NewToolResultText(strings.Join(uris, " ")), nil
+	})
+
+	roots := NewRoots([]string{imports})
+	connect := func(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error) {
+		inProcess := transport.NewInProcessTransportWithOptions(mcpServer, transport.WithRootsHandler(roots))
+		mcpClient := client.NewClient(inProcess, client.WithRootsHandler(roots))
+		if err := mcpClient.Start(ctx); err != nil {
+			return nil, nil, err
+		}
+		return initialize(ctx, mcpClient)
+	}
+	registry := NewRegistry([]domain.ServerConfig{{Name: "files", URL: "in-process"}}, connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	listRoots := func() string {
+		return registry.CallTool(context.Background(), "files__list_roots", []byte(`{}`)).Content[0].OfText.Text
+	}
+	if listed := listRoots(); listed != "file://"+imports {
+		t.Errorf("expected the configured root but got %q", listed)
+	}
+
+	if _, err := roots.Add(exports); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := roots.Add(imports); err == nil {
+		t.Errorf("expected an error adding a root twice")
+	}
+	if _, err := roots.Remove(imports); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	registry.NotifyRootsChanged(context.Background())
+
+	if changes.Load() != 1 {
+		t.Errorf("expected one roots change notification but got %d", changes.Load())
+	}
+	if listed := listRoots(); listed != "file://"+exports {
+		t.Errorf("expected the added root only but got %q", listed)
+	}
+}
+
+func TestRegistry_SharesRootsOverStreamableHTTP(t *testing.T) {
+	imports, exports := t.TempDir(), t.TempDir()
+	listed := make(chan string, 1)
+	mcpServer := server.NewMCPServer("files", "1.0.0")
+	// Servers typically list the roots again when told they changed. The
+	// request reaches the client over its listening stream.
+	mcpServer.AddNotificationHandler(mcp.MethodNotificationRootsListChanged, func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		go func() {
+			result, err := mcpServer.RequestRoots(context.WithoutCancel(ctx), mcp.ListRootsRequest{})
+			if err != nil {
+				listed <- err.Error()
+				return
+			}
+			var uris []string
+			for _, root := range result.Roots {
+				uris = append(uris, root.URI)
+			}
+			listed <- strings.Join(uris, " ")
+		}()
+	})
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer httpServer.Close()
+
+	roots := NewRoots([]string{imports})
+	registry := NewRegistry([]domain.ServerConfig{{Name: "files", URL: httpServer.URL + "/mcp", Transport: domain.TransportStreamableHTTP}},
+		NewDialer(WithRoots(roots)).Connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	if _, err := roots.Add(exports); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	registry.NotifyRootsChanged(context.Background())
+
+	select {
+	case uris := <-listed:
+		if expected := "file://" + imports + " file://" + exports; uris != expected {
+			t.Errorf("expected %q but got %q", expected, uris)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the server to list the roots again")
+	}
+}
+
+func TestRoots_AddRejectsFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customers.csv")
+	if err := os.WriteFile(path, []byte("name\n"), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	if _, err := NewRoots(nil).Add(path); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("expected a not a directory error but got: %v", err)
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	if uri := fileURI("/data/csv imports"); uri != "file:///data/csv%20imports" {
+		t.Errorf("unexpected URI %q", uri)
+	}
+}
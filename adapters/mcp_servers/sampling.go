@@ -0,0 +1,107 @@
+package mcp_servers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mcp_client/core/usecases/sampling"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Sampler runs the model completions servers request
+type Sampler interface {
+	CreateMessage(ctx context.Context, input sampling.CreateMessageInput) (*anthropic.Message, error)
+}
+
+// samplingHandler answers the sampling requests of one server
+type samplingHandler struct {
+	server  string
+	sampler Sampler
+}
+
+var _ client.SamplingHandler = (*samplingHandler)(nil)
+
+// CreateMessage handles sampling/createMessage. Errors, including a request
+// the user declined, are returned to the server as JSON-RPC errors.
+func (h *samplingHandler) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	log.Printf("Server %s requested a completion", h.server)
+
+	input, err := convertSamplingRequest(h.server, request.CreateMessageParams)
+	if err != nil {
+		return nil, err
+	}
+	response, err := h.sampler.CreateMessage(ctx, input)
+	if err != nil {
+		log.Printf("Sampling request of %s failed: %v", h.server, err)
+		return nil, err
+	}
+	return convertSamplingResponse(response), nil
+}
+
+// convertSamplingRequest translates a sampling request into model parameters.
+// The conversation with the user is never shared with servers, so
+// includeContext is ignored.
+func convertSamplingRequest(server string, request mcp.CreateMessageParams) (sampling.CreateMessageInput, error) {
+	input := sampling.CreateMessageInput{
+		Server: server,
+		Params: anthropic.MessageNewParams{
+			MaxTokens:     int64(request.MaxTokens),
+			StopSequences: request.StopSequences,
+		},
+	}
+	for i, samplingMessage := range request.Messages {
+		content, ok := samplingMessage.Content.(mcp.Content)
+		if !ok {
+			return input, fmt.Errorf("message %d has unsupported content %T", i, samplingMessage.Content)
+		}
+		if message, ok := convertMessage(samplingMessage.Role, content); ok {
+			input.Params.Messages = append(input.Params.Messages, message)
+		}
+	}
+	if request.SystemPrompt != "" {
+		input.Params.System = []anthropic.TextBlockParam{{Text: request.SystemPrompt}}
+	}
+	if request.Temperature > 0 {
+		// MCP does not bound the temperature, the Anthropic API accepts up to 1
+		input.Params.Temperature = anthropic.Float(min(request.Temperature, 1))
+	}
+	if request.ModelPreferences != nil {
+		for _, hint := range request.ModelPreferences.Hints {
+			input.ModelHints = append(input.ModelHints, hint.Name)
+		}
+	}
+	return input, nil
+}
+
+// convertSamplingResponse translates the model's response into a sampling result
+func convertSamplingResponse(response *anthropic.Message) *mcp.CreateMessageResult {
+	var text strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text)
+		}
+	}
+
+	stopReason := string(response.StopReason)
+	switch response.StopReason {
+	case anthropic.StopReasonEndTurn:
+		stopReason = "endTurn"
+	case anthropic.StopReasonMaxTokens:
+		stopReason = "maxTokens"
+	case anthropic.StopReasonStopSequence:
+		stopReason = "stopSequence"
+	}
+
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent(text.String()),
+		},
+		Model:      string(response.Model),
+		StopReason: stopReason,
+	}
+}
@@ -0,0 +1,122 @@
+package mcp_servers
+
+import (
+	"context"
+	"errors"
+	"mcp_client/core/domain"
+	"mcp_client/core/usecases/sampling"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mockSampler answers every request with the same text, or fails with err
+type mockSampler struct {
+	inputs []sampling.CreateMessageInput
+	err    error
+}
+
+func (m *mockSampler) CreateMessage(ctx context.Context, input sampling.CreateMessageInput) (*anthropic.Message, error) {
+	m.inputs = append(m.inputs, input)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &anthropic.Message{
+		Model:      "claude-3-5-haiku-latest",
+		Content:    []anthropic.ContentBlockUnion{{Type: "text", Text: "Yes"}},
+		StopReason: anthropic.StopReasonMaxTokens,
+	}, nil
+}
+
+// newSamplingServer creates an in-process server whose "same_person" tool asks the client's model
+func newSamplingServer() *server.MCPServer {
+	mcpServer := server.NewMCPServer("dedupe", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.EnableSampling()
+	mcpServer.AddTool(mcp.NewTool("same_person"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := mcpServer.RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages: []mcp.SamplingMessage{
+					{Role: mcp.RoleUser, Content: mcp.NewTextContent("Are these two customer records the same person?")},
+				},
+				SystemPrompt:     "Answer yes or no.",
+				ModelPreferences: &mcp.ModelPreferences{Hints: []mcp.ModelHint{{Name: "haiku"}}},
+				Temperature:      1.5,
+				MaxTokens:        5,
+			},
+		})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		text, _ := mcp.AsTextContent(result.Content)
+		return mcp.NewToolResultText(text.Text + " (" + result.Model + ", " + result.StopReason + ")"), nil
+	})
+	return mcpServer
+}
+
+func TestRegistry_AnswersSamplingRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		samplerError error
+		expectText   string
+		expectError  bool
+	}{
+		{
+			name:       "answered by the model",
+			expectText: "Yes (claude-3-5-haiku-latest, maxTokens)",
+		},
+		{
+			name:         "declined by the user",
+			samplerError: errors.New("the user declined the sampling request"),
+			expectText:   "the user declined the sampling request",
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler := &mockSampler{err: tt.samplerError}
+			mcpServer := newSamplingServer()
+			connect := func(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error) {
+				mcpClient, err := client.NewInProcessClientWithSamplingHandler(mcpServer, &samplingHandler{server: config.Name, sampler: sampler})
+				if err != nil {
+					return nil, nil, err
+				}
+				if err := mcpClient.Start(ctx); err != nil {
+					return nil, nil, err
+				}
+				return initialize(ctx, mcpClient)
+			}
+			registry := NewRegistry([]domain.ServerConfig{{Name: "dedupe", URL: "in-process"}}, connect)
+			if err := registry.Start(context.Background()); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			defer registry.Close()
+
+			result := registry.CallTool(context.Background(), "dedupe__same_person", []byte(`{}`))
+
+			if result.IsError.Value != tt.expectError {
+				t.Errorf("expected is_error %v but got %v", tt.expectError, result.IsError.Value)
+			}
+			if text := result.Content[0].OfText.Text; !strings.Contains(text, tt.expectText) {
+				t.Errorf("expected result containing %q but got %q", tt.expectText, text)
+			}
+
+			if len(sampler.inputs) != 1 {
+				t.Fatalf("expected one sampling request but got %d", len(sampler.inputs))
+			}
+			input := sampler.inputs[0]
+			params := input.Params
+			if input.Server != "dedupe" || params.MaxTokens != 5 || params.System[0].Text != "Answer yes or no." ||
+				params.Temperature.Value != 1 || len(input.ModelHints) != 1 || input.ModelHints[0] != "haiku" {
+				t.Errorf("unexpected sampling input: %+v", input)
+			}
+			if len(params.Messages) != 1 || params.Messages[0].Content[0].OfText.Text != "Are these two customer records the same person?" {
+				t.Errorf("unexpected messages: %+v", params.Messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package mcp_servers
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"mcp_client/core/domain"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// maxRestartAttempts is how often a crashed stdio server is restarted before giving up
+	maxRestartAttempts = 5
+	// defaultRestartBackoff is the delay before the first restart attempt; it doubles on every failure
+	defaultRestartBackoff = time.Second
+	// restartTimeout bounds starting and initializing a restarted server
+	restartTimeout = 30 * time.Second
+	// defaultExitCheckTimeout bounds the ping confirming that a server exited
+	defaultExitCheckTimeout = 5 * time.Second
+)
+
+// attach makes mcpClient the server's current client and starts watching it
+func (r *Registry) attach(server *Server, mcpClient *client.Client) {
+	mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if lists, ok := changedLists[notification.Method]; ok {
+			server.markStale(lists)
+			return
+		}
+		if notification.Method == progressNotification {
+			r.progressed(notification.Params)
+			return
+		}
+		if notification.Method == mcp.MethodNotificationResourceUpdated {
+			if uri, ok := notification.Params.AdditionalFields["uri"].(string); ok {
+				// Reading the resource needs this goroutine to deliver the response
+				go r.resourceUpdated(server, uri)
+			}
+			return
+		}
+		log.Printf("Received notification from %s: %s", server.Config.Name, notification.Method)
+	})
+
+	server.mu.Lock()
+	if server.closing {
+		// The registry was closed while the server was restarting
+		server.mu.Unlock()
+		mcpClient.Close()
+		return
+	}
+	server.client = mcpClient
+	server.mu.Unlock()
+
+	// Only stdio servers have a stderr stream. It closes when the subprocess
+	// exits, which is the first sign of a crash.
+	if stderr, ok := client.GetStderr(mcpClient); ok {
+		go func() {
+			scanner := bufio.NewScanner(stderr)
+			for scanner.Scan() {
+				log.Printf("[%s] %s", server.Config.Name, scanner.Text())
+			}
+			r.checkExit(server, mcpClient)
+		}()
+	}
+}
+
+// checkExit restarts a stdio server if its subprocess no longer answers. Its
+// stderr closing or a call failing in the transport hint at an exit, but a
+// server may close its stderr and keep running, so a ping confirms it.
+func (r *Registry) checkExit(server *Server, mcpClient *client.Client) {
+	if server.Config.TransportKind() != domain.TransportStdio {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.exitCheckTimeout)
+	err := mcpClient.Ping(ctx)
+	cancel()
+	if err == nil {
+		return
+	}
+	r.handleExit(server, mcpClient)
+}
+
+// handleExit restarts a stdio server whose subprocess exited while the registry still uses it
+func (r *Registry) handleExit(server *Server, exited *client.Client) {
+	server.mu.Lock()
+	closing := server.closing || server.client != exited || server.restarting
+	if !closing {
+		server.restarting = true
+	}
+	server.mu.Unlock()
+	if closing {
+		return
+	}
+	defer func() {
+		server.mu.Lock()
+		server.restarting = false
+		server.mu.Unlock()
+	}()
+
+	log.Printf("Server %s exited unexpectedly, restarting", server.Config.Name)
+	// Reap the subprocess; its exit status is not interesting here.
+	exited.Close()
+
+	backoff := r.restartBackoff
+	for attempt := 1; attempt <= maxRestartAttempts; attempt++ {
+		time.Sleep(backoff)
+		backoff *= 2
+
+		server.mu.RLock()
+		closing := server.closing
+		server.mu.RUnlock()
+		if closing {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), restartTimeout)
+		mcpClient, serverInfo, err := r.connect(ctx, server.Config)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to restart server %s (attempt %d/%d): %v", server.Config.Name, attempt, maxRestartAttempts, err)
+			continue
+		}
+
+		server.mu.Lock()
+		server.Info = serverInfo
+		server.mu.Unlock()
+		r.attach(server, mcpClient)
+		// The restarted server may offer different tools
+		server.markStale(allLists)
+		r.resubscribe(server)
+		log.Printf("Server %s restarted", server.Config.Name)
+		return
+	}
+
+	log.Printf("Giving up on server %s after %d restart attempts", server.Config.Name, maxRestartAttempts)
+}
@@ -0,0 +1,139 @@
+package mcp_servers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// stdioJournalEnv makes the test binary act as a stdio server that records
+// its starts and subscriptions in the file the variable names
+const stdioJournalEnv = "MCP_CLIENT_TEST_STDIO_JOURNAL"
+
+func TestMain(m *testing.M) {
+	if journal := os.Getenv(stdioJournalEnv); journal != "" {
+		serveStdio(journal)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// serveStdio answers just enough of MCP over stdin and stdout for the
+// supervisor test. mcp-go's server does not implement resource subscriptions,
+// so the messages are handled here. The crash tool exits after answering,
+// and servers started after a crash offer a reopen tool as well.
+func serveStdio(journal string) {
+	previous, _ := os.ReadFile(journal)
+	generation := strings.Count(string(previous), "start\n") + 1
+	record := func(line string) {
+		file, err := os.OpenFile(journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return
+		}
+		defer file.Close()
+		fmt.Fprintln(file, line)
+	}
+	record("start")
+
+	tools := []map[string]any{{"name": "crash", "inputSchema": map[string]any{"type": "object"}}}
+	if generation > 1 {
+		tools = append(tools, map[string]any{"name": "reopen", "inputSchema": map[string]any{"type": "object"}})
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var request struct {
+			ID     any             `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &request) != nil || request.ID == nil {
+			continue
+		}
+		var params struct {
+			URI string `json:"uri"`
+		}
+		json.Unmarshal(request.Params, &params)
+
+		var result any = map[string]any{}
+		switch request.Method {
+		case "initialize":
+			result = map[string]any{
+				"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+				"capabilities":    map[string]any{"tools": map[string]any{}, "resources": map[string]any{"subscribe": true}},
+				"serverInfo":      map[string]any{"name": "tickets", "version": "1.0.0"},
+			}
+		case "tools/list":
+			result = map[string]any{"tools": tools}
+		case "resources/list":
+			result = map[string]any{"resources": []map[string]any{{"uri": "tickets://open", "name": "open"}}}
+		case "resources/templates/list":
+			result = map[string]any{"resourceTemplates": []any{}}
+		case "resources/read":
+			result = map[string]any{"contents": []map[string]any{{"uri": params.URI, "text": "ticket 1"}}}
+		case "resources/subscribe":
+			record("subscribe " + params.URI)
+		case "tools/call":
+			encoder.Encode(map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": map[string]any{"content": []any{}}})
+			os.Exit(1)
+		}
+		encoder.Encode(map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}
+}
+
+func TestRegistry_RestartsCrashedStdioServers(t *testing.T) {
+	journal := filepath.Join(t.TempDir(), "journal")
+	config := *domain.NewStdioServerConfig("tickets", os.Args[0])
+	config.Env = map[string]string{stdioJournalEnv: journal}
+	registry := NewRegistry([]domain.ServerConfig{config}, NewDialer().Connect)
+	registry.restartBackoff = time.Millisecond
+	registry.exitCheckTimeout = 100 * time.Millisecond
+	ctx := context.Background()
+	if err := registry.Start(ctx); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+	if _, err := registry.Watch(ctx, "tickets://open"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	crashed := registry.Servers()[0].Client()
+	registry.CallTool(ctx, "tickets__crash", []byte(`{}`))
+
+	deadline := time.Now().Add(5 * time.Second)
+	var entries string
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(journal)
+		entries = string(data)
+		if strings.Count(entries, "subscribe tickets://open") == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if expected := "start\nsubscribe tickets://open\nstart\nsubscribe tickets://open\n"; entries != expected {
+		t.Fatalf("expected the server to be restarted and resubscribed but the journal is %q", entries)
+	}
+	if registry.Servers()[0].Client() == crashed {
+		t.Errorf("expected the crashed client to be replaced")
+	}
+	// The restarted server offers another tool, which the next refresh picks up
+	registry.Refresh()
+	var names []string
+	for _, tool := range registry.Tools() {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "tickets__crash,tickets__reopen" {
+		t.Errorf("expected the tools of the restarted server but got %v", names)
+	}
+}
@@ -0,0 +1,54 @@
+package mcp_servers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var englishPrinter = message.NewPrinter(language.English)
+
+// validateInput checks tool input against the input schema of the tool name. The
+// error lists every violation, such as missing required fields, wrong types
+// or values outside an enum, so the model can correct all of them at once.
+func validateInput(name string, validator *jsonschema.Schema, input []byte) error {
+	if validator == nil {
+		return nil
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(input))
+	if err != nil {
+		return fmt.Errorf("the tool input is not valid JSON: %w", err)
+	}
+
+	err = validator.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	var violations strings.Builder
+	writeViolations(&violations, validationErr)
+	return fmt.Errorf("the input does not match the input schema of %s:%s\nCorrect the input and call the tool again.", name, violations.String())
+}
+
+// writeViolations lists the innermost causes of a validation error, which
+// name the offending values rather than the schemas they failed
+func writeViolations(violations *strings.Builder, err *jsonschema.ValidationError) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			writeViolations(violations, cause)
+		}
+		return
+	}
+
+	location := "/" + strings.Join(err.InstanceLocation, "/")
+	if len(err.InstanceLocation) == 0 {
+		location = "input"
+	}
+	fmt.Fprintf(violations, "\n- %s: %s", location, err.ErrorKind.LocalizedString(englishPrinter))
+}
@@ -0,0 +1,67 @@
+package mcp_servers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateInput(t *testing.T) {
+	tool := mcp.NewToolWithRawSchema("register", "", json.RawMessage(`{
+		"type": "object",
+		"required": ["name", "email"],
+		"properties": {
+			"name": {"type": "string"},
+			"email": {"type": "string", "format": "email"},
+			"plan": {"enum": ["free", "pro"]},
+			"address": {"$ref": "#/$defs/address"}
+		},
+		"$defs": {"address": {"type": "object", "properties": {"zip": {"type": "string"}}}}
+	}`))
+	inputSchema, _ := convertInputSchema(tool)
+	validator := compileValidator(tool, inputSchema)
+
+	tests := []struct {
+		name             string
+		input            string
+		expectViolations []string
+	}{
+		{
+			name:  "valid input",
+			input: `{"name":"Ada","email":"ada@example.com","plan":"pro","address":{"zip":"75001"}}`,
+		},
+		{
+			name:  "every violation is listed",
+			input: `{"email":"ada.example.com","plan":"gold","address":{"zip":75001}}`,
+			expectViolations: []string{
+				"- input: missing property 'name'",
+				"- /email: 'ada.example.com' is not valid email",
+				"- /plan: value must be one of 'free', 'pro'",
+				"- /address/zip: got number, want string",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInput("crm__register", validator, []byte(tt.input))
+
+			if len(tt.expectViolations) == 0 {
+				if err != nil {
+					t.Errorf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error but got none")
+			}
+			for _, violation := range tt.expectViolations {
+				if !strings.Contains(err.Error(), violation) {
+					t.Errorf("expected the error to list %q but got: %v", violation, err)
+				}
+			}
+		})
+	}
+}
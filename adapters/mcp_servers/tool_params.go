@@ -0,0 +1,130 @@
+package mcp_servers
+
+import (
+	"mcp_client/core/domain"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ToolParams returns the catalog as Anthropic tool definitions
+func (r *Registry) ToolParams() []anthropic.ToolUnionParam {
+	toolParams := convertMcpToolToAnthropicTool(r.Tools())
+	toolParams = append(toolParams, convertResourcesToAnthropicTool(r.resourceTools())...)
+	toolParams = append(toolParams, convertResourceListsToAnthropicTool(r.ResourceLists())...)
+	return append(toolParams, convertResourceTemplatesToAnthropicTool(r.ResourceTemplates())...)
+}
+
+func convertMcpToolToAnthropicTool(mcpTools []CatalogTool) []anthropic.ToolUnionParam {
+	anthropicTools := make([]anthropic.ToolParam, len(mcpTools))
+	for i, mcpTool := range mcpTools {
+		anthropicTools[i] = anthropic.ToolParam{
+			Name:        mcpTool.Name,
+			Description: anthropic.String(mcpTool.Tool.Description),
+			InputSchema: toInputSchemaParam(mcpTool.InputSchema),
+		}
+	}
+	return convertToolsToToolUnionParam(anthropicTools)
+}
+
+// toInputSchemaParam carries every keyword of the schema over, the ones
+// without a field of their own as extra fields
+func toInputSchemaParam(schema map[string]any) anthropic.ToolInputSchemaParam {
+	param := anthropic.ToolInputSchemaParam{
+		Properties:  schema["properties"],
+		Required:    toStrings(schema["required"]),
+		ExtraFields: map[string]any{},
+	}
+	for key, value := range schema {
+		switch key {
+		case "type", "properties", "required":
+		default:
+			param.ExtraFields[key] = value
+		}
+	}
+	return param
+}
+
+func convertToolsToToolUnionParam(tools []anthropic.ToolParam) []anthropic.ToolUnionParam {
+	toolUnionParams := make([]anthropic.ToolUnionParam, len(tools))
+	for i, tool := range tools {
+		toolUnionParams[i] = anthropic.ToolUnionParam{
+			OfTool: &tool,
+		}
+	}
+	return toolUnionParams
+}
+
+// Convert MCP resources to Anthropic tools because Claude API's does not support resources.
+func convertResourcesToAnthropicTool(resources []CatalogResource) []anthropic.ToolUnionParam {
+	anthropicTools := make([]anthropic.ToolParam, len(resources))
+	for i, resource := range resources {
+		inputSchema := anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{},
+			Required:   []string{},
+		}
+		anthropicTools[i] = anthropic.ToolParam{
+			Name:        resource.Name,
+			Description: anthropic.String(resource.Resource.Description),
+			InputSchema: inputSchema,
+		}
+	}
+	return convertToolsToToolUnionParam(anthropicTools)
+}
+
+// Convert MCP resource templates to Anthropic tools taking the template variables
+func convertResourceTemplatesToAnthropicTool(templates []CatalogResourceTemplate) []anthropic.ToolUnionParam {
+	anthropicTools := make([]anthropic.ToolParam, len(templates))
+	for i, template := range templates {
+		description := strings.TrimSpace(template.Template.Description + " Reads the resource " + template.Template.URITemplate.Raw() + ".")
+		anthropicTools[i] = anthropic.ToolParam{
+			Name:        template.Name,
+			Description: anthropic.String(description),
+			InputSchema: toInputSchemaParam(template.InputSchema),
+		}
+	}
+	return convertToolsToToolUnionParam(anthropicTools)
+}
+
+// ToolHints returns what the annotations of a tool tell about it. Following
+// MCP, tools that are not read-only are destructive unless they say otherwise.
+// Resources are only read.
+func (r *Registry) ToolHints(name string) domain.ToolHints {
+	for _, tool := range r.Tools() {
+		if tool.Name != name {
+			continue
+		}
+		annotations := tool.Tool.Annotations
+		readOnly := annotations.ReadOnlyHint != nil && *annotations.ReadOnlyHint
+		destructive := annotations.DestructiveHint == nil || *annotations.DestructiveHint
+		return domain.ToolHints{ReadOnly: readOnly, Destructive: destructive && !readOnly}
+	}
+	return domain.ToolHints{ReadOnly: true}
+}
+
+// ToolServer returns the configured name of the server a call is routed to.
+// Namespaced names cannot be split back into it, as server names are
+// sanitized and may contain the separator themselves.
+func (r *Registry) ToolServer(name string) string {
+	for _, entry := range r.Tools() {
+		if entry.Name == name {
+			return entry.Server.Config.Name
+		}
+	}
+	for _, entry := range r.resourceTools() {
+		if entry.Name == name {
+			return entry.Server.Config.Name
+		}
+	}
+	for _, entry := range r.ResourceLists() {
+		if entry.Name == name {
+			return entry.Server.Config.Name
+		}
+	}
+	for _, entry := range r.ResourceTemplates() {
+		if entry.Name == name {
+			return entry.Server.Config.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package mcp_servers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// imageMediaTypes are the image formats the model accepts
+var imageMediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// convertToolResult translates each content item of an MCP tool result into
+// the matching Anthropic block. A result the tool marked as an error becomes
+// an is_error result, so the model does not mistake a failed call for a
+// successful one. Structured content is added as JSON text.
+func convertToolResult(result *mcp.CallToolResult) anthropic.ToolResultBlockParam {
+	var blocks []anthropic.ToolResultBlockParamContentUnion
+	for _, content := range result.Content {
+		if block, ok := convertContent(content); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	if block, ok := structuredContentBlock(result.StructuredContent, result.Content); ok {
+		blocks = append(blocks, block)
+	}
+
+	if result.IsError {
+		if len(blocks) > 0 && blocks[0].OfText != nil {
+			blocks[0] = textBlock("The tool reported an error: " + blocks[0].OfText.Text)
+		} else {
+			blocks = append([]anthropic.ToolResultBlockParamContentUnion{textBlock("The tool reported an error.")}, blocks...)
+		}
+		return anthropic.ToolResultBlockParam{Content: blocks, IsError: anthropic.Bool(true)}
+	}
+	if len(blocks) == 0 {
+		return textToolResult("The tool returned no content.")
+	}
+	return anthropic.ToolResultBlockParam{Content: blocks}
+}
+
+// convertResourceResult translates the contents of a resource read
+func convertResourceResult(result *mcp.ReadResourceResult) anthropic.ToolResultBlockParam {
+	var blocks []anthropic.ToolResultBlockParamContentUnion
+	for _, contents := range result.Contents {
+		if block, ok := convertResourceContents(contents); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return textToolResult("The resource is empty.")
+	}
+	return anthropic.ToolResultBlockParam{Content: blocks}
+}
+
+// convertContent translates one MCP content item. Content the model cannot
+// take is described in a text block instead. Empty text is dropped, as the
+// API rejects empty text blocks.
+func convertContent(content mcp.Content) (anthropic.ToolResultBlockParamContentUnion, bool) {
+	if text, ok := mcp.AsTextContent(content); ok {
+		return textBlock(text.Text), text.Text != ""
+	}
+	if image, ok := mcp.AsImageContent(content); ok {
+		return imageBlock(image.MIMEType, image.Data, "image"), true
+	}
+	if audio, ok := mcp.AsAudioContent(content); ok {
+		return textBlock(fmt.Sprintf("[%s audio omitted, the model cannot listen to audio]", audio.MIMEType)), true
+	}
+	if resource, ok := mcp.AsEmbeddedResource(content); ok {
+		return convertResourceContents(resource.Resource)
+	}
+
+	// Content types newer than this client are passed on as they are
+	data, err := json.Marshal(content)
+	if err != nil {
+		return anthropic.ToolResultBlockParamContentUnion{}, false
+	}
+	return textBlock(string(data)), true
+}
+
+// structuredContentBlock renders the structured content of a result as JSON.
+// Servers usually repeat it as serialized JSON in a text item, and then it is
+// not sent twice.
+func structuredContentBlock(structured any, content []mcp.Content) (anthropic.ToolResultBlockParamContentUnion, bool) {
+	if structured == nil {
+		return anthropic.ToolResultBlockParamContentUnion{}, false
+	}
+	data, err := json.MarshalIndent(structured, "", "  ")
+	if err != nil {
+		return textBlock(fmt.Sprintf("[structured content omitted, it is not valid JSON: %v]", err)), true
+	}
+	for _, item := range content {
+		if text, ok := mcp.AsTextContent(item); ok && sameJSON(text.Text, data) {
+			return anthropic.ToolResultBlockParamContentUnion{}, false
+		}
+	}
+	return textBlock("Structured content:\n" + string(data)), true
+}
+
+// sameJSON reports whether text holds the same JSON value as data
+func sameJSON(text string, data []byte) bool {
+	var value, other any
+	if json.Unmarshal([]byte(text), &value) != nil || json.Unmarshal(data, &other) != nil {
+		return false
+	}
+	return reflect.DeepEqual(value, other)
+}
+
+// convertResourceContents translates the contents of a resource, labelled with its URI
+func convertResourceContents(contents mcp.ResourceContents) (anthropic.ToolResultBlockParamContentUnion, bool) {
+	if text, ok := mcp.AsTextResourceContents(contents); ok {
+		label := text.URI
+		if text.MIMEType != "" {
+			label += " (" + text.MIMEType + ")"
+		}
+		return textBlock(fmt.Sprintf("Resource %s:\n%s", label, text.Text)), true
+	}
+	if blob, ok := mcp.AsBlobResourceContents(contents); ok {
+		return imageBlock(blob.MIMEType, blob.Blob, "resource "+blob.URI), true
+	}
+	return anthropic.ToolResultBlockParamContentUnion{}, false
+}
+
+// imageBlock passes base64 data on as an image when the model accepts its
+// format and describes it otherwise
+func imageBlock(mediaType, data, description string) anthropic.ToolResultBlockParamContentUnion {
+	if !imageMediaTypes[mediaType] {
+		size := base64.StdEncoding.DecodedLen(len(data))
+		return textBlock(fmt.Sprintf("[%s: %s content of about %d bytes omitted, the model cannot read it]", description, mediaType, size))
+	}
+	return anthropic.ToolResultBlockParamContentUnion{
+		OfImage: &anthropic.ImageBlockParam{
+			Source: anthropic.ImageBlockParamSourceUnion{
+				OfBase64: &anthropic.Base64ImageSourceParam{
+					Data:      data,
+					MediaType: anthropic.Base64ImageSourceMediaType(mediaType),
+				},
+			},
+		},
+	}
+}
+
+// callError explains why a call got no result, telling apart failures to
+// reach the server from requests the server refused, so the model can decide
+// whether retrying makes sense.
+func callError(server *Server, err error) error {
+	switch {
+	case errors.Is(err, errCancelledByUser):
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("server %s did not answer in time, the call may or may not have taken effect: %w", server.Config.Name, err)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("the call was cancelled: %w", err)
+	case errors.As(err, new(*transport.Error)):
+		return fmt.Errorf("could not reach server %s, the outcome of the call is unknown: %w", server.Config.Name, err)
+	default:
+		// A JSON-RPC error, e.g. for an unknown tool or invalid arguments
+		return fmt.Errorf("server %s rejected the call: %w", server.Config.Name, err)
+	}
+}
+
+func textBlock(text string) anthropic.ToolResultBlockParamContentUnion {
+	return anthropic.ToolResultBlockParamContentUnion{OfText: &anthropic.TextBlockParam{Text: text}}
+}
+
+func textToolResult(text string) anthropic.ToolResultBlockParam {
+	return anthropic.ToolResultBlockParam{
+		Content: []anthropic.ToolResultBlockParamContentUnion{textBlock(text)},
+	}
+}
+
+func errorToolResult(text string) anthropic.ToolResultBlockParam {
+	result := textToolResult(text)
+	result.IsError = anthropic.Bool(true)
+	return result
+}
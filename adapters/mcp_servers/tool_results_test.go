@@ -0,0 +1,106 @@
+package mcp_servers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestConvertToolResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      *mcp.CallToolResult
+		expectError bool
+		// expectBlocks describes the blocks as "text:<prefix>" or "image:<media type>"
+		expectBlocks []string
+	}{
+		{
+			name:         "text",
+			result:       mcp.NewToolResultText("3 customers"),
+			expectBlocks: []string{"text:3 customers"},
+		},
+		{
+			name:         "chart image with caption",
+			result:       mcp.NewToolResultImage("Signups per day", "iVBORw0KGgo=", "image/png"),
+			expectBlocks: []string{"text:Signups per day", "image:image/png"},
+		},
+		{
+			name: "image format the model cannot read",
+			result: &mcp.CallToolResult{Content: []mcp.Content{
+				mcp.NewImageContent("PHN2Zz4=", "image/svg+xml"),
+			}},
+			expectBlocks: []string{"text:[image: image/svg+xml content"},
+		},
+		{
+			name: "embedded resources",
+			result: &mcp.CallToolResult{Content: []mcp.Content{
+				mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "crm://customers/42", MIMEType: "application/json", Text: `{"name":"Ada"}`}),
+				mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: "reports://signups.png", MIMEType: "image/png", Blob: "iVBORw0KGgo="}),
+				mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: "reports://signups.pdf", MIMEType: "application/pdf", Blob: "JVBERi0="}),
+			}},
+			expectBlocks: []string{
+				"text:Resource crm://customers/42 (application/json):\n{\"name\":\"Ada\"}",
+				"image:image/png",
+				"text:[resource reports://signups.pdf: application/pdf content",
+			},
+		},
+		{
+			name:         "structured content",
+			result:       mcp.NewToolResultStructured(map[string]any{"count": 3}, "3 customers"),
+			expectBlocks: []string{"text:3 customers", "text:Structured content:\n{\n  \"count\": 3\n}"},
+		},
+		{
+			name:         "structured content repeated as text",
+			result:       mcp.NewToolResultStructuredOnly(map[string]any{"count": 3}),
+			expectBlocks: []string{`text:{"count":3}`},
+		},
+		{
+			name:         "structured content only",
+			result:       &mcp.CallToolResult{StructuredContent: []any{"Ada", "Grace"}},
+			expectBlocks: []string{"text:Structured content:\n[\n  \"Ada\","},
+		},
+		{
+			name:         "no content",
+			result:       &mcp.CallToolResult{},
+			expectBlocks: []string{"text:The tool returned no content."},
+		},
+		{
+			name: "error with an image only",
+			result: &mcp.CallToolResult{IsError: true, Content: []mcp.Content{
+				mcp.NewImageContent("iVBORw0KGgo=", "image/png"),
+			}},
+			expectError:  true,
+			expectBlocks: []string{"text:The tool reported an error.", "image:image/png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertToolResult(tt.result)
+
+			if result.IsError.Value != tt.expectError {
+				t.Errorf("expected is_error %v but got %v", tt.expectError, result.IsError.Value)
+			}
+			if len(result.Content) != len(tt.expectBlocks) {
+				t.Fatalf("expected %d blocks but got %d", len(tt.expectBlocks), len(result.Content))
+			}
+			for i, expected := range tt.expectBlocks {
+				block := result.Content[i]
+				kind, value, _ := strings.Cut(expected, ":")
+				switch {
+				case kind == "text" && block.OfText != nil:
+					if !strings.HasPrefix(block.OfText.Text, value) {
+						t.Errorf("expected block %d to start with %q but got %q", i, value, block.OfText.Text)
+					}
+				case kind == "image" && block.OfImage != nil:
+					if mediaType := string(block.OfImage.Source.OfBase64.MediaType); mediaType != value {
+						t.Errorf("expected block %d to be a %s image but got %s", i, value, mediaType)
+					}
+				default:
+					t.Errorf("expected block %d to be %s but got: %+v", i, expected, block)
+				}
+			}
+		})
+	}
+}
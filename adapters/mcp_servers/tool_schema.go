@@ -0,0 +1,296 @@
+package mcp_servers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaCombinators are the keywords the Anthropic API rejects at the top level of an input schema
+var schemaCombinators = []string{"allOf", "anyOf", "oneOf"}
+
+// convertInputSchema turns the input schema of an MCP tool into one the
+// Anthropic API accepts. The schema is preserved as a whole: local $refs are
+// inlined, and only what the API cannot take is changed. Each change is
+// returned as a reason so the caller can report the downgrade.
+func convertInputSchema(tool mcp.Tool) (map[string]any, []string) {
+	schema, err := decodeInputSchema(tool)
+	if err != nil {
+		return emptyObjectSchema(), []string{err.Error() + ", any input is accepted"}
+	}
+
+	var reasons []string
+	schema = inlineRefs(schema, &reasons)
+	schema = flattenTopLevel(schema, &reasons)
+
+	if _, err := compileSchema(schema); err != nil {
+		reasons = append(reasons, fmt.Sprintf("the input schema is invalid, any input is accepted: %v", err))
+		return emptyObjectSchema(), reasons
+	}
+	return schema, reasons
+}
+
+// compileValidator compiles the schema tool inputs are checked against. The
+// schema of the server is preferred; when it cannot be compiled, e.g.
+// because it refers to other documents, the converted schema is used.
+func compileValidator(tool mcp.Tool, converted map[string]any) *jsonschema.Schema {
+	if original, err := decodeInputSchema(tool); err == nil {
+		if validator, err := compileSchema(original); err == nil {
+			return validator
+		}
+	}
+	validator, err := compileSchema(converted)
+	if err != nil {
+		return nil
+	}
+	return validator
+}
+
+// decodeInputSchema returns the input schema of the tool as the server sent it
+func decodeInputSchema(tool mcp.Tool) (map[string]any, error) {
+	raw := tool.RawInputSchema
+	if len(raw) == 0 {
+		var err error
+		if raw, err = json.Marshal(tool.InputSchema); err != nil {
+			return nil, fmt.Errorf("the input schema cannot be encoded: %w", err)
+		}
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil || schema == nil {
+		return nil, fmt.Errorf("the input schema is not a JSON object")
+	}
+	return schema, nil
+}
+
+func emptyObjectSchema() map[string]any {
+	return map[string]any{"type": "object"}
+}
+
+// compileSchema checks that schema is a valid JSON Schema and compiles it,
+// asserting formats such as email so malformed values are caught
+func compileSchema(schema map[string]any) (*jsonschema.Schema, error) {
+	// The compiler expects documents decoded by its own decoder
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err := compiler.AddResource("input.json", document); err != nil {
+		return nil, err
+	}
+	return compiler.Compile("input.json")
+}
+
+// inlineRefs replaces local $refs with the schemas they point to, so the model
+// sees every shape in place. Recursive references cannot be inlined and are
+// kept together with the definitions they need. References to other
+// documents cannot be followed and are replaced by a schema accepting anything.
+func inlineRefs(root map[string]any, reasons *[]string) map[string]any {
+	kept := false
+	var inline func(node any, resolving []string) any
+	inline = func(node any, resolving []string) any {
+		switch node := node.(type) {
+		case map[string]any:
+			if ref, ok := node["$ref"].(string); ok {
+				if !strings.HasPrefix(ref, "#") {
+					*reasons = append(*reasons, fmt.Sprintf("reference %s to another document was dropped", ref))
+					return withoutKey(node, "$ref")
+				}
+				for _, seen := range resolving {
+					if seen == ref {
+						kept = true
+						return node
+					}
+				}
+				target, ok := resolvePointer(root, ref)
+				if !ok {
+					*reasons = append(*reasons, fmt.Sprintf("unresolvable reference %s was dropped", ref))
+					return withoutKey(node, "$ref")
+				}
+				// Keywords next to $ref, like a description, refine the referenced schema
+				merged := inline(target, append(resolving, ref))
+				if mergedMap, ok := merged.(map[string]any); ok {
+					result := make(map[string]any, len(mergedMap)+len(node))
+					for key, value := range mergedMap {
+						result[key] = value
+					}
+					for key, value := range withoutKey(node, "$ref") {
+						result[key] = inline(value, resolving)
+					}
+					return result
+				}
+				return merged
+			}
+
+			result := make(map[string]any, len(node))
+			for key, value := range node {
+				result[key] = inline(value, resolving)
+			}
+			return result
+		case []any:
+			result := make([]any, len(node))
+			for i, value := range node {
+				result[i] = inline(value, resolving)
+			}
+			return result
+		default:
+			return node
+		}
+	}
+
+	definitions := map[string]any{}
+	for _, key := range []string{"$defs", "definitions"} {
+		if value, ok := root[key]; ok {
+			definitions[key] = value
+		}
+	}
+	inlined := inline(withoutKey(withoutKey(root, "$defs"), "definitions"), nil).(map[string]any)
+	if kept {
+		for key, value := range definitions {
+			inlined[key] = value
+		}
+	}
+	return inlined
+}
+
+// resolvePointer follows a JSON pointer fragment such as #/$defs/address
+func resolvePointer(root map[string]any, ref string) (any, bool) {
+	var node any = root
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return node, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = object[token]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// flattenTopLevel makes sure the schema describes an object without
+// top-level combinators. The properties of allOf, anyOf and oneOf branches
+// are merged; only properties every branch requires stay required.
+func flattenTopLevel(schema map[string]any, reasons *[]string) map[string]any {
+	if schemaType, ok := schema["type"]; ok && schemaType != "object" {
+		*reasons = append(*reasons, fmt.Sprintf("the input schema describes %v instead of an object, any input is accepted", schemaType))
+		return emptyObjectSchema()
+	}
+	schema["type"] = "object"
+
+	for _, combinator := range schemaCombinators {
+		branches, ok := schema[combinator].([]any)
+		if !ok {
+			continue
+		}
+		delete(schema, combinator)
+		*reasons = append(*reasons, fmt.Sprintf("top-level %s was merged into the properties", combinator))
+
+		properties, _ := schema["properties"].(map[string]any)
+		if properties == nil {
+			properties = map[string]any{}
+		}
+		required := toStrings(schema["required"])
+		var shared []string
+		for i, branch := range branches {
+			branch, ok := branch.(map[string]any)
+			if !ok {
+				continue
+			}
+			if branchProperties, ok := branch["properties"].(map[string]any); ok {
+				for name, property := range branchProperties {
+					if _, exists := properties[name]; !exists {
+						properties[name] = property
+					}
+				}
+			}
+			branchRequired := toStrings(branch["required"])
+			switch {
+			case combinator == "allOf":
+				required = append(required, branchRequired...)
+			case i == 0:
+				shared = branchRequired
+			default:
+				shared = intersect(shared, branchRequired)
+			}
+		}
+		schema["properties"] = properties
+		if required = unique(append(required, shared...)); len(required) > 0 {
+			schema["required"] = toAnySlice(required)
+		}
+	}
+	return schema
+}
+
+func withoutKey(node map[string]any, key string) map[string]any {
+	result := make(map[string]any, len(node))
+	for k, value := range node {
+		if k != key {
+			result[k] = value
+		}
+	}
+	return result
+}
+
+func toStrings(value any) []string {
+	values, _ := value.([]any)
+	var result []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func toAnySlice(values []string) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
+
+func intersect(a, b []string) []string {
+	var result []string
+	for _, value := range a {
+		for _, other := range b {
+			if value == other {
+				result = append(result, value)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,124 @@
+package mcp_servers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestConvertInputSchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		tool          mcp.Tool
+		expectSchema  string
+		expectReasons []string
+	}{
+		{
+			name:         "structured schema",
+			tool:         mcp.NewTool("lookup", mcp.WithString("id", mcp.Required())),
+			expectSchema: `{"type":"object","properties":{"id":{"type":"string"}},"required":["id"]}`,
+		},
+		{
+			name: "nested definitions are inlined",
+			tool: mcp.NewToolWithRawSchema("register", "", json.RawMessage(`{
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"billing": {"$ref": "#/$defs/address", "description": "Billing address"},
+					"shipping": {"$ref": "#/$defs/address"}
+				},
+				"$defs": {
+					"address": {"type": "object", "properties": {"city": {"$ref": "#/$defs/city"}}},
+					"city": {"type": "string", "enum": ["Paris", "Lyon"]}
+				}
+			}`)),
+			expectSchema: `{"type":"object","additionalProperties":false,"properties":{
+				"billing":{"type":"object","description":"Billing address","properties":{"city":{"type":"string","enum":["Paris","Lyon"]}}},
+				"shipping":{"type":"object","properties":{"city":{"type":"string","enum":["Paris","Lyon"]}}}}}`,
+		},
+		{
+			name: "recursive definitions are kept",
+			tool: mcp.NewToolWithRawSchema("tree", "", json.RawMessage(`{
+				"type": "object",
+				"properties": {"root": {"$ref": "#/$defs/node"}},
+				"$defs": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}}}}
+			}`)),
+			expectSchema: `{"type":"object",
+				"properties":{"root":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/node"}}}}},
+				"$defs":{"node":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/node"}}}}}}`,
+		},
+		{
+			name:          "reference to another document",
+			tool:          mcp.NewToolWithRawSchema("remote", "", json.RawMessage(`{"type":"object","properties":{"a":{"$ref":"https://example.com/a.json"}}}`)),
+			expectSchema:  `{"type":"object","properties":{"a":{}}}`,
+			expectReasons: []string{"reference https://example.com/a.json to another document was dropped"},
+		},
+		{
+			name: "top-level anyOf",
+			tool: mcp.NewToolWithRawSchema("find", "", json.RawMessage(`{"anyOf": [
+				{"properties": {"id": {"type": "string"}, "tenant": {"type": "string"}}, "required": ["id", "tenant"]},
+				{"properties": {"email": {"type": "string"}}, "required": ["email", "tenant"]}
+			]}`)),
+			expectSchema: `{"type":"object","required":["tenant"],
+				"properties":{"id":{"type":"string"},"tenant":{"type":"string"},"email":{"type":"string"}}}`,
+			expectReasons: []string{"top-level anyOf was merged into the properties"},
+		},
+		{
+			name:          "not an object",
+			tool:          mcp.NewToolWithRawSchema("list", "", json.RawMessage(`{"type":"array"}`)),
+			expectSchema:  `{"type":"object"}`,
+			expectReasons: []string{"the input schema describes array instead of an object"},
+		},
+		{
+			name:          "invalid schema",
+			tool:          mcp.NewToolWithRawSchema("broken", "", json.RawMessage(`{"type":"object","properties":{"id":{"type":5}}}`)),
+			expectSchema:  `{"type":"object"}`,
+			expectReasons: []string{"the input schema is invalid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, reasons := convertInputSchema(tt.tool)
+
+			var expected map[string]any
+			if err := json.Unmarshal([]byte(tt.expectSchema), &expected); err != nil {
+				t.Fatalf("invalid expected schema: %v", err)
+			}
+			actualJSON, _ := json.Marshal(schema)
+			expectedJSON, _ := json.Marshal(expected)
+			if string(actualJSON) != string(expectedJSON) {
+				t.Errorf("expected schema %s but got %s", expectedJSON, actualJSON)
+			}
+
+			if len(reasons) != len(tt.expectReasons) {
+				t.Fatalf("expected reasons %q but got %q", tt.expectReasons, reasons)
+			}
+			for i, reason := range reasons {
+				if !strings.HasPrefix(reason, tt.expectReasons[i]) {
+					t.Errorf("expected reason starting with %q but got %q", tt.expectReasons[i], reason)
+				}
+			}
+		})
+	}
+}
+
+func TestToInputSchemaParam_KeepsEveryKeyword(t *testing.T) {
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"id": map[string]any{"type": "string"}},
+		"required":             []any{"id"},
+		"additionalProperties": false,
+	}
+
+	data, err := json.Marshal(toInputSchemaParam(schema))
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	expected := `{"properties":{"id":{"type":"string"}},"required":["id"],"type":"object","additionalProperties":false}`
+	if string(data) != expected {
+		t.Errorf("expected %s but got %s", expected, data)
+	}
+}
@@ -0,0 +1,196 @@
+package mcp_servers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResourceUpdate reports a change of a watched resource
+type ResourceUpdate struct {
+	Server string
+	URI    string
+	// Diff lists the removed and added lines, prefixed with "- " and "+ "
+	Diff string
+	// Text is the new content of the resource
+	Text string
+}
+
+// watch is a subscription to a resource together with its last known content
+type watch struct {
+	server *Server
+	uri    string
+	text   string
+}
+
+// OnResourceUpdated sets the handler receiving the changes of watched resources.
+// It is called on a goroutine of its own for every change.
+func (r *Registry) OnResourceUpdated(handler func(update ResourceUpdate)) {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	r.onResourceUpdated = handler
+}
+
+// Watch subscribes to a resource, given by URI or namespaced name, and returns its URI
+func (r *Registry) Watch(ctx context.Context, target string) (string, error) {
+	server, uri, err := r.findResourceServer(target)
+	if err != nil {
+		return "", err
+	}
+	server.mu.RLock()
+	resources := server.Info.Capabilities.Resources
+	server.mu.RUnlock()
+	if resources == nil || !resources.Subscribe {
+		return "", fmt.Errorf("server %s does not support resource subscriptions", server.Config.Name)
+	}
+
+	r.watchMu.Lock()
+	_, watching := r.watches[uri]
+	r.watchMu.Unlock()
+	if watching {
+		return "", fmt.Errorf("already watching %s", uri)
+	}
+
+	text, err := readText(ctx, server, uri)
+	if err != nil {
+		return "", err
+	}
+	if err := server.Client().Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: uri}}); err != nil {
+		return "", callError(server, err)
+	}
+
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	if r.watches == nil {
+		r.watches = make(map[string]*watch)
+	}
+	r.watches[uri] = &watch{server: server, uri: uri, text: text}
+	return uri, nil
+}
+
+// Unwatch ends the subscription to a resource, given by URI or namespaced name, and returns its URI
+func (r *Registry) Unwatch(ctx context.Context, target string) (string, error) {
+	r.watchMu.Lock()
+	w, ok := r.watches[target]
+	if !ok {
+		if _, uri, err := r.findResourceServer(target); err == nil {
+			w, ok = r.watches[uri]
+		}
+	}
+	if ok {
+		delete(r.watches, w.uri)
+	}
+	r.watchMu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("not watching %s", target)
+	}
+
+	if err := w.server.Client().Unsubscribe(ctx, mcp.UnsubscribeRequest{Params: mcp.UnsubscribeParams{URI: w.uri}}); err != nil {
+		// The subscription is gone on our side either way
+		log.Printf("Failed to unsubscribe from %s: %v", w.uri, err)
+	}
+	return w.uri, nil
+}
+
+// Watches returns the URIs of the watched resources
+func (r *Registry) Watches() []string {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	uris := make([]string, 0, len(r.watches))
+	for uri := range r.watches {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+	return uris
+}
+
+// findResourceServer returns the server offering a resource, looked up by
+// namespaced name, by URI, or by a resource template the URI matches
+func (r *Registry) findResourceServer(target string) (*Server, string, error) {
+	for _, resource := range r.Resources() {
+		if resource.Name == target || resource.Resource.URI == target {
+			return resource.Server, resource.Resource.URI, nil
+		}
+	}
+	for _, template := range r.ResourceTemplates() {
+		if template.Template.URITemplate.Regexp().MatchString(target) {
+			return template.Server, target, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no server offers the resource %s", target)
+}
+
+// resourceUpdated reads a watched resource again after its server announced
+// a change, and reports the change if the content differs. The announced URI
+// may be a sub-resource of the watched one.
+func (r *Registry) resourceUpdated(server *Server, uri string) {
+	r.watchMu.Lock()
+	var updated *watch
+	for _, w := range r.watches {
+		if w.server == server && (w.uri == uri || strings.HasPrefix(uri, strings.TrimSuffix(w.uri, "/")+"/")) {
+			updated = w
+			break
+		}
+	}
+	r.watchMu.Unlock()
+	if updated == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	text, err := readText(ctx, server, updated.uri)
+	if err != nil {
+		log.Printf("Failed to read the changed resource %s: %v", updated.uri, err)
+		return
+	}
+
+	r.watchMu.Lock()
+	diff := diffLines(updated.text, text)
+	updated.text = text
+	handler := r.onResourceUpdated
+	r.watchMu.Unlock()
+	if diff == "" || handler == nil {
+		return
+	}
+	handler(ResourceUpdate{Server: server.Config.Name, URI: updated.uri, Diff: diff, Text: text})
+}
+
+// resubscribe renews the subscriptions of a restarted server
+func (r *Registry) resubscribe(server *Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	for _, uri := range r.Watches() {
+		r.watchMu.Lock()
+		w := r.watches[uri]
+		r.watchMu.Unlock()
+		if w == nil || w.server != server {
+			continue
+		}
+		if err := server.Client().Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: uri}}); err != nil {
+			log.Printf("Failed to watch %s again: %v", uri, err)
+		}
+	}
+}
+
+// readText reads a resource and returns its content as text, describing content that is not text
+func readText(ctx context.Context, server *Server, uri string) (string, error) {
+	result, err := readResource(ctx, server, uri)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, content := range result.Content {
+		switch {
+		case content.OfText != nil:
+			parts = append(parts, content.OfText.Text)
+		case content.OfImage != nil:
+			parts = append(parts, "[image]")
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
@@ -0,0 +1,213 @@
+package mcp_servers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mcp_client/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// subscribingTransport answers subscriptions, which the in-process server does
+// not implement, and lets the test deliver notifications
+type subscribingTransport struct {
+	*transport.InProcessTransport
+
+	mu            sync.Mutex
+	subscriptions []string
+	notify        func(notification mcp.JSONRPCNotification)
+}
+
+func (t *subscribingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	switch request.Method {
+	case "resources/subscribe":
+		t.mu.Lock()
+		t.subscriptions = append(t.subscriptions, request.Params.(mcp.SubscribeParams).URI)
+		t.mu.Unlock()
+	case "resources/unsubscribe":
+	default:
+		return t.InProcessTransport.SendRequest(ctx, request)
+	}
+	return &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: []byte(`{}`)}, nil
+}
+
+func (t *subscribingTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notify = handler
+}
+
+func TestRegistry_WatchReportsResourceUpdates(t *testing.T) {
+	var mu sync.Mutex
+	tickets := "#1 Printer jammed\n#2 Password reset"
+	mcpServer := server.NewMCPServer("helpdesk", "1.0.0", server.WithResourceCapabilities(true, false))
+	mcpServer.AddResource(mcp.NewResource("tickets://open", "open_tickets"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: tickets}}, nil
+	})
+
+	fake := &subscribingTransport{InProcessTransport: transport.NewInProcessTransport(mcpServer)}
+	connect := func(ctx context.Context, config domain.ServerConfig) (*client.Client, *mcp.InitializeResult, error) {
+		mcpClient := client.NewClient(fake)
+		if err := mcpClient.Start(ctx); err != nil {
+			return nil, nil, err
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		serverInfo, err := mcpClient.Initialize(ctx, initRequest)
+		return mcpClient, serverInfo, err
+	}
+	registry := NewRegistry([]domain.ServerConfig{{Name: "helpdesk", URL: "in-process"}}, connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+
+	updates := make(chan ResourceUpdate, 1)
+	registry.OnResourceUpdated(func(update ResourceUpdate) { updates <- update })
+
+	uri, err := registry.Watch(context.Background(), "helpdesk__open_tickets")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if uri != "tickets://open" || len(fake.subscriptions) != 1 || fake.subscriptions[0] != "tickets://open" {
+		t.Errorf("expected a subscription to tickets://open but got %s, %v", uri, fake.subscriptions)
+	}
+	if _, err := registry.Watch(context.Background(), "tickets://open"); err == nil {
+		t.Errorf("expected watching twice to fail")
+	}
+
+	mu.Lock()
+	tickets = "#2 Password reset\n#3 VPN down"
+	mu.Unlock()
+	notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+	notification.Method = string(mcp.MethodNotificationResourceUpdated)
+	notification.Params.AdditionalFields = map[string]any{"uri": "tickets://open"}
+	fake.mu.Lock()
+	notify := fake.notify
+	fake.mu.Unlock()
+	notify(notification)
+
+	select {
+	case update := <-updates:
+		expected := "- #1 Printer jammed\n+ #3 VPN down"
+		if update.Server != "helpdesk" || update.URI != "tickets://open" || update.Diff != expected {
+			t.Errorf("expected diff %q but got %+v", expected, update)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected an update but got none")
+	}
+
+	if _, err := registry.Unwatch(context.Background(), "tickets://open"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if watches := registry.Watches(); len(watches) != 0 {
+		t.Errorf("expected no watches but got %v", watches)
+	}
+}
+
+// subscribingHandler answers subscriptions in front of a Streamable HTTP
+// server, which does not implement them, and reports the subscribed URIs
+func subscribingHandler(next http.Handler, subscribed chan<- string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			var request struct {
+				ID     any    `json:"id"`
+				Method string `json:"method"`
+				Params struct {
+					URI string `json:"uri"`
+				} `json:"params"`
+			}
+			if json.Unmarshal(body, &request) == nil && request.Method == "resources/subscribe" {
+				subscribed <- request.Params.URI
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": request.ID, "result": map[string]any{}})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRegistry_WatchOverStreamableHTTP(t *testing.T) {
+	var mu sync.Mutex
+	tickets := "#1 Printer jammed"
+	mcpServer := server.NewMCPServer("helpdesk", "1.0.0", server.WithResourceCapabilities(true, false))
+	mcpServer.AddResource(mcp.NewResource("tickets://open", "open_tickets"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: tickets}}, nil
+	})
+	subscribed := make(chan string, 1)
+	httpServer := httptest.NewServer(subscribingHandler(server.NewStreamableHTTPServer(mcpServer), subscribed))
+	defer httpServer.Close()
+
+	registry := NewRegistry([]domain.ServerConfig{{Name: "helpdesk", URL: httpServer.URL, Transport: domain.TransportStreamableHTTP}},
+		NewDialer().Connect)
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer registry.Close()
+	updates := make(chan ResourceUpdate, 1)
+	registry.OnResourceUpdated(func(update ResourceUpdate) { updates <- update })
+
+	if _, err := registry.Watch(context.Background(), "tickets://open"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if uri := <-subscribed; uri != "tickets://open" {
+		t.Errorf("expected a subscription to tickets://open but got %s", uri)
+	}
+	// Give the client time to open its listening stream
+	time.Sleep(100 * time.Millisecond)
+
+	// The update arrives while no request is in flight
+	mu.Lock()
+	tickets = "#1 Printer jammed\n#2 VPN down"
+	mu.Unlock()
+	mcpServer.SendNotificationToAllClients(string(mcp.MethodNotificationResourceUpdated), map[string]any{"uri": "tickets://open"})
+
+	select {
+	case update := <-updates:
+		if update.Diff != "+ #2 VPN down" {
+			t.Errorf("expected the added ticket but got %+v", update)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected an update but got none")
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{name: "equal", before: "a\nb", after: "a\nb", expected: ""},
+		{name: "added line", before: "a\nc", after: "a\nb\nc", expected: "+ b"},
+		{name: "removed line", before: "a\nb\nc", after: "a\nc", expected: "- b"},
+		{name: "changed line", before: "a\nb\nc", after: "a\nB\nc", expected: "- b\n+ B"},
+		{name: "from empty", before: "", after: "a", expected: "+ a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := diffLines(tt.before, tt.after); diff != tt.expected {
+				t.Errorf("expected %q but got %q", tt.expected, diff)
+			}
+		})
+	}
+}
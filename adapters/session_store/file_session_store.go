@@ -0,0 +1,124 @@
+package session_store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp_client/core/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// sessionFile is the layout of a saved session
+type sessionFile struct {
+	domain.Session
+	Messages []anthropic.MessageParam `json:"messages"`
+}
+
+// FileSessionStore keeps every session as a JSON file named after its ID
+type FileSessionStore struct {
+	dir string
+}
+
+// NewFileSessionStore creates a store saving sessions in dir, which is created on first save
+func NewFileSessionStore(dir string) *FileSessionStore {
+	return &FileSessionStore{
+		dir: dir,
+	}
+}
+
+// Save writes the session, replacing the previous version atomically so a crash never leaves a torn file
+func (s *FileSessionStore) Save(session domain.Session, messages []anthropic.MessageParam) error {
+	if !session.IsValid() {
+		return fmt.Errorf("invalid session ID %q", session.ID)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	session.MessageCount = len(messages)
+	data, err := json.MarshalIndent(sessionFile{Session: session, Messages: messages}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	temp, err := os.CreateTemp(s.dir, session.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := os.Rename(temp.Name(), s.path(session.ID)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Load reads a saved session
+func (s *FileSessionStore) Load(id string) (*domain.Session, []anthropic.MessageParam, error) {
+	if !(&domain.Session{ID: id}).IsValid() {
+		return nil, nil, fmt.Errorf("invalid session ID %q", id)
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("session %s not found", id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var file sessionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse session %s: %w", id, err)
+	}
+	return &file.Session, file.Messages, nil
+}
+
+// List returns the saved sessions, most recently updated first
+func (s *FileSessionStore) List() ([]domain.Session, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var sessions []domain.Session
+	for _, entry := range entries {
+		id, isSession := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !isSession {
+			continue
+		}
+		// Only the metadata is needed, so the messages are skipped
+		data, err := os.ReadFile(s.path(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		var session domain.Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return nil, fmt.Errorf("failed to parse session %s: %w", id, err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+func (s *FileSessionStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,54 @@
+package session_store
+
+import (
+	"mcp_client/core/domain"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestFileSessionStore_SaveLoadList(t *testing.T) {
+	store := NewFileSessionStore(t.TempDir())
+	older := domain.NewSession("claude-test", "ada", time.Now().Add(-time.Hour))
+	newer := domain.NewSession("claude-test", "ada", time.Now())
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("Who is customer 42?")),
+		anthropic.NewAssistantMessage(anthropic.NewToolUseBlock("toolu_1", map[string]any{"id": "42"}, "crm__lookup")),
+		anthropic.NewUserMessage(anthropic.NewToolResultBlock("toolu_1", "Ada", false)),
+	}
+
+	for _, session := range []*domain.Session{older, newer} {
+		if err := store.Save(*session, messages); err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+	}
+
+	session, loaded, err := store.Load(older.ID)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if session.MessageCount != 3 || len(loaded) != 3 {
+		t.Fatalf("expected 3 messages but got count %d and %d messages", session.MessageCount, len(loaded))
+	}
+	toolUse := loaded[1].Content[0].OfToolUse
+	if toolUse == nil || toolUse.Name != "crm__lookup" {
+		t.Errorf("expected the tool call to survive the round trip but got: %+v", loaded[1].Content[0])
+	}
+	toolResult := loaded[2].Content[0].OfToolResult
+	if toolResult == nil || toolResult.ToolUseID != "toolu_1" || toolResult.Content[0].OfText.Text != "Ada" {
+		t.Errorf("expected the tool result to survive the round trip but got: %+v", loaded[2].Content[0])
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != newer.ID {
+		t.Errorf("expected the newest session first but got: %+v", sessions)
+	}
+
+	if _, _, err := store.Load("../escape"); err == nil {
+		t.Errorf("expected an error for an ID outside the sessions directory")
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Kinds of audit entries
+const (
+	AuditModelTurn = "model_turn"
+	AuditToolCall  = "tool_call"
+)
+
+// How a tool call was cleared to run, or why it was not
+const (
+	// ClearedByPolicy calls were allowed by the approval policy of the tool
+	ClearedByPolicy = "policy"
+	// ClearedByUser calls were approved by the user in ApprovedBy
+	ClearedByUser = "user"
+	// ClearedDenied calls were denied by the policy or the user, or could not be approved
+	ClearedDenied = "denied"
+	// ClearedDryRun calls were simulated and never reached the server
+	ClearedDryRun = "dry_run"
+)
+
+// AuditEntry is a line of the audit log, recording either a model turn or a tool call
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Session string    `json:"session"`
+	// Operator is the user running the client, on whose behalf every model
+	// turn and tool call happens, whoever approved it
+	Operator string `json:"operator,omitempty"`
+	// ResponseID is the ID of the model response, for tool calls the one that asked for the call
+	ResponseID string `json:"response_id,omitempty"`
+	// RequestID is the API's ID of the request that got the response
+	RequestID string `json:"request_id,omitempty"`
+
+	// Model turns
+	Model        string `json:"model,omitempty"`
+	StopReason   string `json:"stop_reason,omitempty"`
+	InputTokens  int64  `json:"input_tokens,omitempty"`
+	OutputTokens int64  `json:"output_tokens,omitempty"`
+
+	// Tool calls
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Server    string `json:"server,omitempty"`
+	// Tool is the namespaced name of the tool, e.g. crm__register_customer
+	Tool      string          `json:"tool,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// Cleared is one of the Cleared constants
+	Cleared    string `json:"cleared,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+	// ResultSize and ResultSHA256 describe the result as JSON without storing it
+	ResultSize   int    `json:"result_size,omitempty"`
+	ResultSHA256 string `json:"result_sha256,omitempty"`
+	Error        string `json:"error,omitempty"`
+	DurationMS   int64  `json:"duration_ms,omitempty"`
+}
+
+// NewToolCallEntry starts the audit entry of a call of the namespaced tool name on a server
+func NewToolCallEntry(session, server, name string, arguments []byte, started time.Time) AuditEntry {
+	return AuditEntry{
+		Time:      started,
+		Kind:      AuditToolCall,
+		Session:   session,
+		Server:    server,
+		Tool:      name,
+		Arguments: json.RawMessage(arguments),
+	}
+}
+
+// SetResult records the size and hash of a result and how long the call took
+func (e *AuditEntry) SetResult(result []byte, finished time.Time) {
+	sum := sha256.Sum256(result)
+	e.ResultSize = len(result)
+	e.ResultSHA256 = hex.EncodeToString(sum[:])
+	e.DurationMS = finished.Sub(e.Time).Milliseconds()
+}
+
+// AuditSettings controls the audit log
+type AuditSettings struct {
+	// File is the JSON Lines file entries are appended to; empty disables the audit log
+	File string
+	// Redact names the arguments whose values are left out of the log, either
+	// for every tool ("email") or for one ("crm__register_customer.email")
+	Redact []string
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// ChatSettings controls how conversations with the model are run
+type ChatSettings struct {
+	Model        string
+	MaxTokens    int64
+	SystemPrompt string
+	// ModelTimeout bounds a single request to the model
+	ModelTimeout time.Duration
+	// ToolTimeout bounds a single tool call or resource read
+	ToolTimeout time.Duration
+	// Tools holds per tool settings keyed by namespaced tool name
+	Tools map[string]ToolConfig
+	// ContextWindow is the model's context size in tokens; zero disables compaction
+	ContextWindow int64
+	// CompactThreshold is the fraction of the context window at which the conversation is compacted
+	CompactThreshold float64
+	// ToolResultLimit is how many characters of an older tool result survive compaction
+	ToolResultLimit int
+	// DryRun simulates the calls of mutating tools instead of making them
+	DryRun bool
+	// Operator names the user running the client for the audit log and new sessions
+	Operator string
+}
+
+// CompactionLimit returns the number of input tokens at which the conversation is compacted
+func (s *ChatSettings) CompactionLimit() int64 {
+	return int64(float64(s.ContextWindow) * s.CompactThreshold)
+}
+
+// Tool returns the settings of a tool, which are zero for tools that are not configured
+func (s *ChatSettings) Tool(name string) ToolConfig {
+	return s.Tools[name]
+}
+
+// How changes of watched resources are reported
+const (
+	// ResourceUpdatesShow prints the changes in the terminal
+	ResourceUpdatesShow = "show"
+	// ResourceUpdatesConversation tells the model about the changes with the next user message
+	ResourceUpdatesConversation = "conversation"
+)
+
+// ClientConfig holds everything the client needs to run
+type ClientConfig struct {
+	Servers []ServerConfig
+	Chat    ChatSettings
+	// Sampling controls the model completions servers may request
+	Sampling SamplingSettings
+	// ConnectTimeout bounds connecting to and initializing the servers
+	ConnectTimeout time.Duration
+	// APIKey authenticates with the Anthropic API
+	APIKey string
+	// SessionsDir is where conversations are saved
+	SessionsDir string
+	// Roots are the local directories servers may work in
+	Roots []string
+	// Audit controls the log of model turns and tool calls
+	Audit AuditSettings
+	// ResourceUpdates is how changes of watched resources are reported, see ResourceUpdatesShow
+	ResourceUpdates string
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field types of a Form
+const (
+	FieldString  = "string"
+	FieldNumber  = "number"
+	FieldInteger = "integer"
+	FieldBoolean = "boolean"
+)
+
+// Answers to a Form
+const (
+	FormAccept  = "accept"
+	FormDecline = "decline"
+	FormCancel  = "cancel"
+)
+
+// Form is a set of fields a server asks the user to fill in
+type Form struct {
+	// Message explains what is asked for and why
+	Message string
+	Fields  []FormField
+}
+
+// FormField is a single value of a form, with the constraints it must meet
+type FormField struct {
+	Name        string
+	Title       string
+	Description string
+	// Type is one of the Field constants
+	Type     string
+	Required bool
+	Default  any
+	// Enum lists the allowed values of a string field, EnumNames their display names
+	Enum      []string
+	EnumNames []string
+	// Format of a string field: email, uri, date or date-time
+	Format    string
+	MinLength *int
+	MaxLength *int
+	Minimum   *float64
+	Maximum   *float64
+}
+
+// FormResponse is the user's answer to a form. Values are only set when the form is accepted.
+type FormResponse struct {
+	// Action is one of FormAccept, FormDecline and FormCancel
+	Action string
+	Values map[string]any
+}
+
+// Label returns the title of the field, or its name when it has none
+func (f *FormField) Label() string {
+	if f.Title != "" {
+		return f.Title
+	}
+	return f.Name
+}
+
+// Parse converts what the user typed into a value of the field's type and
+// checks it. Empty input gives the default, or nil when the field may be left out.
+func (f *FormField) Parse(input string) (any, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		if f.Default != nil {
+			return f.Default, nil
+		}
+		if f.Required {
+			return nil, fmt.Errorf("%s is required", f.Label())
+		}
+		return nil, nil
+	}
+
+	switch f.Type {
+	case FieldBoolean:
+		switch strings.ToLower(input) {
+		case "y", "yes", "true":
+			return true, nil
+		case "n", "no", "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("answer yes or no")
+	case FieldNumber:
+		number, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", input)
+		}
+		if err := f.checkRange(number); err != nil {
+			return nil, err
+		}
+		return number, nil
+	case FieldInteger:
+		integer, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", input)
+		}
+		if err := f.checkRange(float64(integer)); err != nil {
+			return nil, err
+		}
+		return integer, nil
+	default:
+		return f.parseString(input)
+	}
+}
+
+func (f *FormField) parseString(input string) (any, error) {
+	if len(f.Enum) > 0 {
+		// Choices may be picked by number, value or display name
+		if i, err := strconv.Atoi(input); err == nil && i >= 1 && i <= len(f.Enum) {
+			return f.Enum[i-1], nil
+		}
+		if slices.Contains(f.Enum, input) {
+			return input, nil
+		}
+		if i := slices.Index(f.EnumNames, input); i >= 0 && i < len(f.Enum) {
+			return f.Enum[i], nil
+		}
+		return nil, fmt.Errorf("choose one of %s", strings.Join(f.Enum, ", "))
+	}
+
+	length := len([]rune(input))
+	if f.MinLength != nil && length < *f.MinLength {
+		return nil, fmt.Errorf("must be at least %d characters", *f.MinLength)
+	}
+	if f.MaxLength != nil && length > *f.MaxLength {
+		return nil, fmt.Errorf("must be at most %d characters", *f.MaxLength)
+	}
+
+	var err error
+	switch f.Format {
+	case "email":
+		_, err = mail.ParseAddress(input)
+	case "uri":
+		var parsed *url.URL
+		if parsed, err = url.Parse(input); err == nil && parsed.Scheme == "" {
+			err = fmt.Errorf("missing scheme")
+		}
+	case "date":
+		_, err = time.Parse(time.DateOnly, input)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid %s: %v", input, f.Format, err)
+	}
+	return input, nil
+}
+
+func (f *FormField) checkRange(number float64) error {
+	if f.Minimum != nil && number < *f.Minimum {
+		return fmt.Errorf("must be at least %g", *f.Minimum)
+	}
+	if f.Maximum != nil && number > *f.Maximum {
+		return fmt.Errorf("must be at most %g", *f.Maximum)
+	}
+	return nil
+}
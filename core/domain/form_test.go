@@ -0,0 +1,52 @@
+package domain
+
+import "testing"
+
+func TestFormField_Parse(t *testing.T) {
+	one := 1.0
+	three := 3
+	tests := []struct {
+		name        string
+		field       FormField
+		input       string
+		expectValue any
+		expectError bool
+	}{
+		{name: "string", field: FormField{Type: FieldString}, input: " Ada ", expectValue: "Ada"},
+		{name: "too short", field: FormField{Type: FieldString, MinLength: &three}, input: "Al", expectError: true},
+		{name: "email", field: FormField{Type: FieldString, Format: "email"}, input: "ada@example.com", expectValue: "ada@example.com"},
+		{name: "invalid email", field: FormField{Type: FieldString, Format: "email"}, input: "ada", expectError: true},
+		{name: "date", field: FormField{Type: FieldString, Format: "date"}, input: "2026-10-16", expectValue: "2026-10-16"},
+		{name: "enum by number", field: FormField{Type: FieldString, Enum: []string{"free", "pro"}}, input: "2", expectValue: "pro"},
+		{name: "enum by display name", field: FormField{Type: FieldString, Enum: []string{"free", "pro"}, EnumNames: []string{"Free", "Pro"}}, input: "Pro", expectValue: "pro"},
+		{name: "enum mismatch", field: FormField{Type: FieldString, Enum: []string{"free", "pro"}}, input: "gold", expectError: true},
+		{name: "integer", field: FormField{Type: FieldInteger, Minimum: &one}, input: "5", expectValue: int64(5)},
+		{name: "integer below minimum", field: FormField{Type: FieldInteger, Minimum: &one}, input: "0", expectError: true},
+		{name: "fraction for integer", field: FormField{Type: FieldInteger}, input: "1.5", expectError: true},
+		{name: "number", field: FormField{Type: FieldNumber}, input: "1.5", expectValue: 1.5},
+		{name: "boolean", field: FormField{Type: FieldBoolean}, input: "Yes", expectValue: true},
+		{name: "not a boolean", field: FormField{Type: FieldBoolean}, input: "maybe", expectError: true},
+		{name: "empty gives default", field: FormField{Type: FieldBoolean, Default: false, Required: true}, input: "", expectValue: false},
+		{name: "empty optional", field: FormField{Type: FieldString}, input: "", expectValue: nil},
+		{name: "empty required", field: FormField{Type: FieldString, Required: true}, input: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.field.Parse(tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got value %v", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if value != tt.expectValue {
+				t.Errorf("expected %v (%T) but got %v (%T)", tt.expectValue, tt.expectValue, value, value)
+			}
+		})
+	}
+}
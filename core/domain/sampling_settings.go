@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// SamplingSettings controls the model completions servers may request from the client
+type SamplingSettings struct {
+	// Model answers requests whose model hints match none of Models
+	Model string
+	// Models are the models a server may pick through its model hints
+	Models []string
+	// MaxTokens caps the response length of a single request
+	MaxTokens int64
+	// TokenBudget is how many input and output tokens each server may use; zero disables sampling
+	TokenBudget int64
+	// AutoApprove sends requests to the model without asking the user first
+	AutoApprove bool
+	// ModelTimeout bounds a single request to the model
+	ModelTimeout time.Duration
+}
+
+// Enabled reports whether servers may request completions at all
+func (s *SamplingSettings) Enabled() bool {
+	return s.TokenBudget > 0
+}
+
+// SelectModel returns the first of Model and Models matching a hint, evaluating
+// the hints in order. A hint matches every model whose name contains it, so
+// "haiku" picks a Haiku model. Without a match the default Model is used.
+func (s *SamplingSettings) SelectModel(hints []string) string {
+	for _, hint := range hints {
+		if hint == "" {
+			continue
+		}
+		for _, model := range append([]string{s.Model}, s.Models...) {
+			if strings.Contains(model, hint) {
+				return model
+			}
+		}
+	}
+	return s.Model
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Transports supported for connecting to an MCP server
+const (
+	TransportStreamableHTTP = "http"
+	TransportSSE            = "sse"
+	TransportStdio          = "stdio"
+)
+
+// ServerConfig describes an MCP server the client connects to
+type ServerConfig struct {
+	// Name is used to namespace the server's tools and resources, e.g. "crm"
+	Name string `json:"name" yaml:"name"`
+	// Transport is one of the Transport constants. When empty it is inferred
+	// from whether URL or Command is set, and URL servers may fall back to SSE.
+	Transport string `json:"transport,omitempty" yaml:"transport,omitempty"`
+	URL       string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Command, Args and Env describe the subprocess of a stdio server
+	Command string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// MaxConcurrency limits how many tool calls run on the server at once. Zero means the default.
+	MaxConcurrency int `json:"max_concurrency,omitempty" yaml:"max_concurrency,omitempty"`
+	// MaxListPages limits how many pages of each list, such as the resources, are fetched. Zero means the default.
+	MaxListPages int `json:"max_list_pages,omitempty" yaml:"max_list_pages,omitempty"`
+}
+
+// NewServerConfig creates a new ServerConfig instance for an HTTP server
+func NewServerConfig(name, url string) *ServerConfig {
+	return &ServerConfig{
+		Name: name,
+		URL:  url,
+	}
+}
+
+// NewStdioServerConfig creates a new ServerConfig instance for a server launched as a subprocess
+func NewStdioServerConfig(name, command string, args ...string) *ServerConfig {
+	return &ServerConfig{
+		Name:      name,
+		Transport: TransportStdio,
+		Command:   command,
+		Args:      args,
+	}
+}
+
+// TransportKind returns the transport to use, inferring it when not set explicitly
+func (s *ServerConfig) TransportKind() string {
+	if s.Transport != "" {
+		return s.Transport
+	}
+	if s.Command != "" {
+		return TransportStdio
+	}
+	return TransportStreamableHTTP
+}
+
+// AllowsSSEFallback reports whether a Streamable HTTP server that rejects the
+// connection may be retried with the legacy SSE transport
+func (s *ServerConfig) AllowsSSEFallback() bool {
+	return s.Transport == "" && s.Command == ""
+}
+
+// EnvList returns Env as sorted KEY=VALUE pairs
+func (s *ServerConfig) EnvList() []string {
+	env := make([]string, 0, len(s.Env))
+	for key, value := range s.Env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(env)
+	return env
+}
+
+// IsValid validates the ServerConfig
+func (s *ServerConfig) IsValid() bool {
+	if s.Name == "" {
+		return false
+	}
+	switch s.TransportKind() {
+	case TransportStreamableHTTP, TransportSSE:
+		return s.URL != ""
+	case TransportStdio:
+		return s.Command != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSessionTitleLength is how much of the first message is kept as the session title
+const maxSessionTitleLength = 80
+
+// Session describes a saved conversation
+type Session struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Model string `json:"model"`
+	// Operator is the user who started the session
+	Operator  string    `json:"operator,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// MessageCount is the number of messages in the conversation
+	MessageCount int `json:"message_count"`
+	// Compaction shortens what the model is sent of the conversation
+	Compaction Compaction `json:"compaction"`
+}
+
+// Compaction describes how a long conversation is shortened for the model.
+// The messages themselves are kept in full for the transcript.
+type Compaction struct {
+	// Summary stands in for the first SummarizedMessages messages
+	Summary            string `json:"summary,omitempty"`
+	SummarizedMessages int    `json:"summarized_messages,omitempty"`
+	// ElidedMessages is how many messages are sent with their tool results cut short
+	ElidedMessages int `json:"elided_messages,omitempty"`
+}
+
+// NewSession creates a new Session instance with a fresh ID such as "20261016-182501-3f9a"
+func NewSession(model, operator string, now time.Time) *Session {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return &Session{
+		ID:        now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix),
+		Model:     model,
+		Operator:  operator,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// SetTitleFrom names the session after its first message unless it already has a title
+func (s *Session) SetTitleFrom(text string) {
+	if s.Title != "" {
+		return
+	}
+	title := strings.Join(strings.Fields(text), " ")
+	if len(title) > maxSessionTitleLength {
+		// Cut on a rune boundary so the title stays valid UTF-8
+		cut := maxSessionTitleLength
+		for cut > 0 && !utf8.RuneStart(title[cut]) {
+			cut--
+		}
+		title = strings.TrimSpace(title[:cut]) + "..."
+	}
+	s.Title = title
+}
+
+// IsValid validates the Session
+func (s *Session) IsValid() bool {
+	return s.ID != "" && !strings.ContainsAny(s.ID, `/\.`)
+}
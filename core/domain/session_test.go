@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSession_SetTitleFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		title       string
+		text        string
+		expectTitle string
+	}{
+		{name: "short message", text: "Which customers\n signed up today?", expectTitle: "Which customers signed up today?"},
+		{name: "long message", text: strings.Repeat("a", 100), expectTitle: strings.Repeat("a", 80) + "..."},
+		// "é" takes two bytes, so byte 80 falls in the middle of the 40th one
+		{name: "non-ASCII message", text: "x" + strings.Repeat("é", 60), expectTitle: "x" + strings.Repeat("é", 39) + "..."},
+		{name: "already titled", title: "Signups", text: "Something else", expectTitle: "Signups"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &Session{Title: tt.title}
+			session.SetTitleFrom(tt.text)
+			if session.Title != tt.expectTitle {
+				t.Errorf("expected title %q but got %q", tt.expectTitle, session.Title)
+			}
+			if !utf8.ValidString(session.Title) {
+				t.Errorf("expected a valid UTF-8 title but got %q", session.Title)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "regexp"
+
+// NamespaceSeparator joins a server name and a tool or resource name, e.g. "crm__register_customer"
+const NamespaceSeparator = "__"
+
+// Approval policies of tool calls
+const (
+	// ApprovalAllow runs calls right away
+	ApprovalAllow = "allow"
+	// ApprovalAsk runs calls once the user approved them
+	ApprovalAsk = "ask"
+	// ApprovalDeny never runs calls
+	ApprovalDeny = "deny"
+)
+
+// ToolConfig holds the per tool settings of the config file, keyed by namespaced tool name
+type ToolConfig struct {
+	// Exclusive tools never run at the same time as another tool call,
+	// e.g. because they write to a record other calls may touch.
+	Exclusive bool `json:"exclusive,omitempty" yaml:"exclusive,omitempty"`
+	// Approval is one of the Approval policies. When empty the hints of the
+	// tool decide, see ToolHints.Approval.
+	Approval string `json:"approval,omitempty" yaml:"approval,omitempty"`
+	// AskIf is a regular expression. Calls whose JSON input matches it are
+	// asked about unless the tool is denied; the others follow Approval,
+	// which then defaults to allow.
+	AskIf string `json:"ask_if,omitempty" yaml:"ask_if,omitempty"`
+	// Mutating tells whether the tool changes data, overriding its hints. Dry runs simulate mutating tools.
+	Mutating *bool `json:"mutating,omitempty" yaml:"mutating,omitempty"`
+}
+
+// Mutates reports whether calls of the tool may change data
+func (c ToolConfig) Mutates(hints ToolHints) bool {
+	if c.Mutating != nil {
+		return *c.Mutating
+	}
+	return !hints.ReadOnly
+}
+
+// Policy returns the approval policy of a call of the tool with input
+func (c ToolConfig) Policy(input []byte, hints ToolHints) string {
+	if c.Approval == ApprovalDeny {
+		return ApprovalDeny
+	}
+	if c.AskIf != "" {
+		// The pattern is checked when the config is validated
+		if matched, _ := regexp.Match(c.AskIf, input); matched {
+			return ApprovalAsk
+		}
+		if c.Approval == "" {
+			return ApprovalAllow
+		}
+	}
+	if c.Approval != "" {
+		return c.Approval
+	}
+	return hints.Approval()
+}
+
+// ToolHints describe what a tool does, as its server announces it
+type ToolHints struct {
+	// ReadOnly tools do not change anything
+	ReadOnly bool
+	// Destructive tools may delete or overwrite data
+	Destructive bool
+}
+
+// Approval returns the policy for tools without one configured: destructive
+// tools are asked about, the others allowed
+func (h ToolHints) Approval() string {
+	if h.Destructive && !h.ReadOnly {
+		return ApprovalAsk
+	}
+	return ApprovalAllow
+}
+
+// ToolCallDecision is the answer of the user to a tool call needing approval
+type ToolCallDecision struct {
+	Approved bool
+	// Input replaces the input of an approved call when the user edited it
+	Input []byte
+	// Reason tells the model why a call was denied
+	Reason string
+	// User names who decided, for the audit log
+	User string
+}
@@ -0,0 +1,9 @@
+package ports
+
+import "mcp_client/core/domain"
+
+// AuditLogPort defines the interface for recording what happened in conversations
+type AuditLogPort interface {
+	// Record appends an entry to the audit log
+	Record(entry domain.AuditEntry) error
+}
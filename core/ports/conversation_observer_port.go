@@ -0,0 +1,10 @@
+package ports
+
+// ConversationObserverPort defines the interface for following a conversation as it happens, e.g. to print it
+type ConversationObserverPort interface {
+	// OnTextDelta receives response text while it is being generated
+	OnTextDelta(text string)
+	// OnText receives each complete text block once its deltas have been delivered
+	OnText(text string)
+	OnToolUse(name string, input []byte)
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// LanguageModelPort defines the interface for sending a conversation to the model
+type LanguageModelPort interface {
+	// CreateMessage returns the model's complete response. onTextDelta is
+	// called with each piece of text as soon as the model produces it.
+	CreateMessage(ctx context.Context, params anthropic.MessageNewParams, onTextDelta func(text string)) (*ModelResponse, error)
+	// CountTokens returns the number of input tokens the request would use
+	CountTokens(ctx context.Context, params anthropic.MessageNewParams) (int64, error)
+}
+
+// ModelResponse is a complete response of the model
+type ModelResponse struct {
+	*anthropic.Message
+	// RequestID is the ID the API gave the request, as support asks for it
+	RequestID string
+}
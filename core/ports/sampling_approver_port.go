@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// SamplingApproverPort defines the interface for letting the user decide on model completions requested by servers
+type SamplingApproverPort interface {
+	// ApproveSampling reports whether the request of server may be sent to the
+	// model. remaining is what is left of the server's token budget.
+	ApproveSampling(ctx context.Context, server string, params anthropic.MessageNewParams, remaining int64) (bool, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"mcp_client/core/domain"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// SessionStorePort defines the interface for persisting conversations
+type SessionStorePort interface {
+	Save(session domain.Session, messages []anthropic.MessageParam) error
+	Load(id string) (*domain.Session, []anthropic.MessageParam, error)
+	// List returns the saved sessions, most recently updated first
+	List() ([]domain.Session, error)
+}
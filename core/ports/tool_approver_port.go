@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"mcp_client/core/domain"
+)
+
+// ToolApproverPort defines the interface for letting the user decide on tool calls the model asks for
+type ToolApproverPort interface {
+	// ApproveToolCall asks whether the tool may be called with input, which the user may edit
+	ApproveToolCall(ctx context.Context, name string, input []byte) (domain.ToolCallDecision, error)
+}
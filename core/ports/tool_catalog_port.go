@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"mcp_client/core/domain"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ToolCatalogPort defines the interface for the tools offered to the model
+type ToolCatalogPort interface {
+	// Refresh brings the tools up to date with changes the servers announced.
+	// It is called once before every model request.
+	Refresh()
+	// ToolParams returns the tool definitions to send with every model request
+	ToolParams() []anthropic.ToolUnionParam
+	// CallTool runs a tool the model asked for. The caller fills in ToolUseID.
+	CallTool(ctx context.Context, name string, input []byte) anthropic.ToolResultBlockParam
+	// ToolHints tells what a tool does, which decides whether calls need approval by default
+	ToolHints(name string) domain.ToolHints
+	// ToolServer returns the configured name of the server a tool belongs to, empty for unknown tools
+	ToolServer(name string) string
+}
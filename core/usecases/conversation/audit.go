@@ -0,0 +1,64 @@
+package conversation
+
+import (
+	"encoding/json"
+	"log"
+	"mcp_client/core/domain"
+	"mcp_client/core/ports"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// auditModelTurn records a model response in the audit log
+func (u *ConversationUsecase) auditModelTurn(response *ports.ModelResponse) {
+	u.audit(domain.AuditEntry{
+		Time:         time.Now(),
+		Kind:         domain.AuditModelTurn,
+		Session:      u.session.ID,
+		Operator:     u.settings.Operator,
+		ResponseID:   response.ID,
+		RequestID:    response.RequestID,
+		Model:        string(response.Model),
+		StopReason:   string(response.StopReason),
+		InputTokens:  response.Usage.InputTokens,
+		OutputTokens: response.Usage.OutputTokens,
+	})
+}
+
+// auditToolCall completes the audit entry of a tool call with its result and records it
+func (u *ConversationUsecase) auditToolCall(entry domain.AuditEntry, result anthropic.ToolResultBlockParam) {
+	CompleteToolCallEntry(&entry, result, time.Now())
+	u.audit(entry)
+}
+
+// CompleteToolCallEntry records the result of a tool call in its audit entry:
+// the size and hash of the content, the error text of a failed call, and how
+// long the call took
+func CompleteToolCallEntry(entry *domain.AuditEntry, result anthropic.ToolResultBlockParam, finished time.Time) {
+	content, err := json.Marshal(result.Content)
+	if err != nil {
+		log.Printf("Failed to marshal the result of %s for the audit log: %v", entry.Tool, err)
+	}
+	entry.SetResult(content, finished)
+	if result.IsError.Value {
+		var text []string
+		for _, block := range result.Content {
+			if block.OfText != nil {
+				text = append(text, block.OfText.Text)
+			}
+		}
+		entry.Error = strings.Join(text, "\n")
+	}
+}
+
+// audit records an entry. A failure must not end the conversation, so it is only logged.
+func (u *ConversationUsecase) audit(entry domain.AuditEntry) {
+	if u.auditLog == nil {
+		return
+	}
+	if err := u.auditLog.Record(entry); err != nil {
+		log.Printf("Failed to record %s in the audit log: %v", entry.Kind, err)
+	}
+}
@@ -0,0 +1,226 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+const (
+	// summaryPrompt is the system prompt of the request summarizing older turns
+	summaryPrompt = "You summarize conversations between a user and an assistant using tools. " +
+		"Keep every fact, identifier, decision and open question needed to continue the conversation. " +
+		"Answer with the summary only."
+	// summaryPrefix introduces the summary that replaces the older turns
+	summaryPrefix = "Summary of the earlier conversation:\n\n"
+	// charsPerToken underestimates the characters per token of typical text,
+	// so the local estimate errs on the side of counting too many tokens
+	charsPerToken = 3
+)
+
+// compact keeps what the model is sent of the conversation within the context
+// window. When the next request would cross the compaction limit, large tool
+// results the model has already seen are elided first; if that is not enough,
+// the turns before the current one, starting at index turn, are replaced by a
+// summary. Only the session's Compaction changes, the messages stay complete.
+// Failures are logged and leave the conversation as it is, letting the request go ahead.
+func (u *ConversationUsecase) compact(ctx context.Context, turn int) {
+	if u.settings.ContextWindow <= 0 {
+		return
+	}
+	limit := u.settings.CompactionLimit()
+	tokens := u.countTokens(ctx, limit)
+	if tokens < limit {
+		return
+	}
+
+	// The last message may hold tool results the model has not seen yet
+	u.session.Compaction.ElidedMessages = len(u.messages) - 1
+	elided := u.countTokens(ctx, limit)
+	if elided < limit {
+		log.Printf("Compacted the conversation from %d to %d tokens by eliding tool results", tokens, elided)
+		return
+	}
+
+	// The history starts at the message after the summarized ones
+	summarized := u.session.Compaction.SummarizedMessages
+	if turn <= summarized {
+		log.Printf("The conversation uses %d tokens but the current turn cannot be summarized", elided)
+		return
+	}
+	summary, err := u.summarize(ctx, u.history()[:turn-summarized])
+	if err != nil {
+		log.Printf("Failed to compact the conversation: %v", err)
+		return
+	}
+
+	u.session.Compaction.Summary = summary
+	u.session.Compaction.SummarizedMessages = turn
+	log.Printf("Compacted the conversation from %d to %d tokens by summarizing %d messages", tokens, u.countTokens(ctx, limit), turn)
+}
+
+// history returns the conversation as the model is sent it: the summarized
+// messages are left out, the summary opening the first message after them,
+// and tool results of the elided messages are cut short
+func (u *ConversationUsecase) history() []anthropic.MessageParam {
+	compaction := u.session.Compaction
+	summarized := min(compaction.SummarizedMessages, len(u.messages))
+	elided := min(max(compaction.ElidedMessages, summarized), len(u.messages))
+	history := append(elideToolResults(u.messages[summarized:elided], u.settings.ToolResultLimit), u.messages[elided:]...)
+	if summarized == 0 || len(history) == 0 {
+		return history
+	}
+
+	// The summary joins the message opening the current turn so the roles keep alternating
+	opening := history[0]
+	history[0] = anthropic.MessageParam{
+		Role:    opening.Role,
+		Content: append([]anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(summaryPrefix + compaction.Summary)}, opening.Content...),
+	}
+	return history
+}
+
+// countTokens returns the number of input tokens of the next request. The API
+// is only asked when the local estimate comes close to limit.
+func (u *ConversationUsecase) countTokens(ctx context.Context, limit int64) int64 {
+	params := u.messageParams()
+	data, err := json.Marshal(params)
+	if err != nil {
+		return 0
+	}
+	estimate := int64(len(data) / charsPerToken)
+	if estimate < limit {
+		return estimate
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ModelTimeout)
+	defer cancel()
+	tokens, err := u.model.CountTokens(ctx, params)
+	if err != nil {
+		log.Printf("Failed to count tokens, using an estimate: %v", err)
+		return estimate
+	}
+	return tokens
+}
+
+// summarize asks the model for a summary of the messages. They are sent as a
+// transcript, so no tool definitions are needed and tool results stay short.
+func (u *ConversationUsecase) summarize(ctx context.Context, messages []anthropic.MessageParam) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ModelTimeout)
+	defer cancel()
+
+	response, err := u.model.CreateMessage(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(u.settings.Model),
+		MaxTokens: u.settings.MaxTokens,
+		System:    []anthropic.TextBlockParam{{Text: summaryPrompt}},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(transcript(messages, u.settings.ToolResultLimit))),
+		},
+	}, func(string) {})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize: %w", err)
+	}
+
+	var summary strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			summary.WriteString(content.Text)
+		}
+	}
+	if summary.Len() == 0 {
+		return "", fmt.Errorf("failed to summarize: the model returned no text")
+	}
+	return summary.String(), nil
+}
+
+// transcript renders messages as plain text for summarizing
+func transcript(messages []anthropic.MessageParam, toolResultLimit int) string {
+	var text strings.Builder
+	for _, message := range messages {
+		speaker := "User"
+		if message.Role == anthropic.MessageParamRoleAssistant {
+			speaker = "Assistant"
+		}
+		for _, content := range message.Content {
+			switch {
+			case content.OfText != nil:
+				fmt.Fprintf(&text, "%s: %s\n\n", speaker, content.OfText.Text)
+			case content.OfToolUse != nil:
+				input, _ := json.Marshal(content.OfToolUse.Input)
+				fmt.Fprintf(&text, "Assistant called %s with %s\n\n", content.OfToolUse.Name, input)
+			case content.OfToolResult != nil:
+				for _, result := range content.OfToolResult.Content {
+					if result.OfText != nil {
+						fmt.Fprintf(&text, "Tool result: %s\n\n", elide(result.OfText.Text, toolResultLimit))
+					} else if result.OfImage != nil {
+						text.WriteString("Tool result: [image]\n\n")
+					}
+				}
+			}
+		}
+	}
+	return text.String()
+}
+
+// elideToolResults returns a copy of messages with the text of tool results
+// cut to limit characters and their images replaced by a note. The
+// tool_result blocks themselves are kept, so every tool_use still has its result.
+func elideToolResults(messages []anthropic.MessageParam, limit int) []anthropic.MessageParam {
+	elided := make([]anthropic.MessageParam, len(messages))
+	for i, message := range messages {
+		elided[i] = message
+		copied := false
+		for j, content := range message.Content {
+			if content.OfToolResult == nil || !needsEliding(content.OfToolResult, limit) {
+				continue
+			}
+			// Copy on write, the messages are kept in full for the transcript
+			if !copied {
+				elided[i].Content = append([]anthropic.ContentBlockParamUnion(nil), message.Content...)
+				copied = true
+			}
+			result := *content.OfToolResult
+			result.Content = make([]anthropic.ToolResultBlockParamContentUnion, len(content.OfToolResult.Content))
+			for k, block := range content.OfToolResult.Content {
+				if block.OfText != nil {
+					block = anthropic.ToolResultBlockParamContentUnion{
+						OfText: &anthropic.TextBlockParam{Text: elide(block.OfText.Text, limit)},
+					}
+				} else if block.OfImage != nil {
+					block = anthropic.ToolResultBlockParamContentUnion{
+						OfText: &anthropic.TextBlockParam{Text: "[image elided to save context]"},
+					}
+				}
+				result.Content[k] = block
+			}
+			elided[i].Content[j] = anthropic.ContentBlockParamUnion{OfToolResult: &result}
+		}
+	}
+	return elided
+}
+
+func needsEliding(result *anthropic.ToolResultBlockParam, limit int) bool {
+	for _, block := range result.Content {
+		if block.OfImage != nil || block.OfText != nil && len(block.OfText.Text) > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// elide cuts text to about limit characters, never splitting one, and notes how much was left out
+func elide(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n[%d characters elided to save context]", text[:cut], utf8.RuneCountInString(text[cut:]))
+}
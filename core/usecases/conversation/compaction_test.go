@@ -0,0 +1,149 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+const summaryResponse = `{"role":"assistant","content":[{"type":"text","text":"Customer 42 is Ada."}]}`
+
+// earlierTurn is a finished turn with a tool call, preceding the turn under test
+func earlierTurn(question, toolResult string) []anthropic.MessageParam {
+	return []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(question)),
+		anthropic.NewAssistantMessage(anthropic.NewToolUseBlock("toolu_0", map[string]any{"id": "42"}, "crm__lookup")),
+		anthropic.NewUserMessage(anthropic.NewToolResultBlock("toolu_0", toolResult, false)),
+		anthropic.NewAssistantMessage(anthropic.NewTextBlock("The customer is Ada.")),
+	}
+}
+
+func TestConversationUsecase_Compaction(t *testing.T) {
+	tests := []struct {
+		name           string
+		earlier        []anthropic.MessageParam
+		responses      []string
+		expectSummary  bool
+		expectMessages int
+		expectElided   bool
+	}{
+		{
+			name:           "small conversation is left alone",
+			earlier:        earlierTurn("Who is customer 42?", "Ada"),
+			responses:      []string{textResponse},
+			expectMessages: 5,
+		},
+		{
+			name:           "large tool results are elided first",
+			earlier:        earlierTurn("Who is customer 42?", strings.Repeat("customer record ", 1000)),
+			responses:      []string{textResponse},
+			expectMessages: 5,
+			expectElided:   true,
+		},
+		{
+			name:           "older turns are summarized when eliding is not enough",
+			earlier:        earlierTurn(strings.Repeat("a long question ", 1000), "Ada"),
+			responses:      []string{summaryResponse, textResponse},
+			expectSummary:  true,
+			expectMessages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockSessionStore()
+			model := &mockModel{responses: tt.responses}
+			settings := testSettings
+			settings.ContextWindow = 2000
+			settings.CompactThreshold = 0.5
+			settings.ToolResultLimit = 100
+			usecase := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, settings)
+			store.Save(usecase.Session(), tt.earlier)
+			if err := usecase.Resume(usecase.Session().ID); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			if _, err := usecase.Send(context.Background(), SendInput{Text: "And customer 43?"}); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			request := model.requests[len(model.requests)-1]
+			if len(request.Messages) != tt.expectMessages {
+				t.Fatalf("expected %d messages sent to the model but got %d", tt.expectMessages, len(request.Messages))
+			}
+			if tt.expectSummary {
+				if model.requests[0].System[0].Text != summaryPrompt {
+					t.Errorf("expected the first request to ask for a summary")
+				}
+				first := request.Messages[0].Content
+				if first[0].OfText.Text != summaryPrefix+"Customer 42 is Ada." || first[1].OfText.Text != "And customer 43?" {
+					t.Errorf("expected the summary to open the current turn but got: %+v", first)
+				}
+			} else {
+				result := request.Messages[2].Content[0].OfToolResult
+				if result == nil || result.ToolUseID != "toolu_0" {
+					t.Fatalf("expected the tool result to stay paired with its tool use but got: %+v", request.Messages[2].Content[0])
+				}
+				elided := strings.Contains(result.Content[0].OfText.Text, "characters elided")
+				if elided != tt.expectElided {
+					t.Errorf("expected elided=%v but got %q", tt.expectElided, result.Content[0].OfText.Text)
+				}
+				if tt.expectElided && len(tt.earlier[2].Content[0].OfToolResult.Content[0].OfText.Text) != 16000 {
+					t.Errorf("expected eliding to leave the original messages untouched")
+				}
+			}
+		})
+	}
+}
+
+func TestConversationUsecase_CompactionKeepsTranscript(t *testing.T) {
+	store := newMockSessionStore()
+	settings := testSettings
+	settings.ContextWindow = 2000
+	settings.CompactThreshold = 0.5
+	settings.ToolResultLimit = 100
+	earlier := earlierTurn(strings.Repeat("a long question ", 1000), strings.Repeat("customer record ", 1000))
+	usecase := NewConversationUsecase(&mockModel{responses: []string{summaryResponse, textResponse}},
+		&mockTools{}, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, settings)
+	id := usecase.Session().ID
+	store.Save(usecase.Session(), earlier)
+	if err := usecase.Resume(id); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "And customer 43?"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	saved := store.messages[id]
+	if len(saved) != 6 {
+		t.Fatalf("expected the saved transcript to keep all 6 messages but got %d", len(saved))
+	}
+	if saved[0].Content[0].OfText.Text != earlier[0].Content[0].OfText.Text {
+		t.Errorf("expected the summarized question to be saved in full")
+	}
+	if text := saved[2].Content[0].OfToolResult.Content[0].OfText.Text; text != strings.Repeat("customer record ", 1000) {
+		t.Errorf("expected the tool result to be saved in full but got %d characters", len(text))
+	}
+	if compaction := store.sessions[id].Compaction; compaction.SummarizedMessages != 4 || compaction.Summary != "Customer 42 is Ada." {
+		t.Errorf("expected the compaction to be saved with the session but got %+v", compaction)
+	}
+
+	// A resumed session is sent the summary without summarizing again
+	model := &mockModel{responses: []string{textResponse}}
+	resumed := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, settings)
+	if err := resumed.Resume(id); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := resumed.Send(context.Background(), SendInput{Text: "Thanks"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(model.requests) != 1 {
+		t.Fatalf("expected one request but got %d", len(model.requests))
+	}
+	request := model.requests[0].Messages
+	if len(request) != 3 || request[0].Content[0].OfText.Text != summaryPrefix+"Customer 42 is Ada." {
+		t.Errorf("expected the summary to open the resumed conversation but got %d messages", len(request))
+	}
+}
@@ -0,0 +1,422 @@
+package conversation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mcp_client/core/domain"
+	"mcp_client/core/ports"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Input structs for the usecase
+type SendInput struct {
+	Text string
+}
+
+// ConversationUsecase runs a conversation with the model, executing the tools it
+// asks for once their approval policy allows. The conversation is saved as a
+// session after every step.
+type ConversationUsecase struct {
+	model    ports.LanguageModelPort
+	tools    ports.ToolCatalogPort
+	approver ports.ToolApproverPort
+	observer ports.ConversationObserverPort
+	sessions ports.SessionStorePort
+	auditLog ports.AuditLogPort
+	settings domain.ChatSettings
+	session  *domain.Session
+	messages []anthropic.MessageParam
+
+	// notesMu guards notes, which may be added while a message is being sent
+	notesMu sync.Mutex
+	notes   []string
+}
+
+// NewConversationUsecase creates a new instance of the usecase with a new
+// session. approver and auditLog may be nil: calls needing approval are then
+// denied, and nothing is audited.
+func NewConversationUsecase(
+	model ports.LanguageModelPort,
+	tools ports.ToolCatalogPort,
+	approver ports.ToolApproverPort,
+	observer ports.ConversationObserverPort,
+	sessions ports.SessionStorePort,
+	auditLog ports.AuditLogPort,
+	settings domain.ChatSettings,
+) *ConversationUsecase {
+	return &ConversationUsecase{
+		model:    model,
+		tools:    tools,
+		approver: approver,
+		observer: observer,
+		sessions: sessions,
+		auditLog: auditLog,
+		settings: settings,
+		session:  domain.NewSession(settings.Model, settings.Operator, time.Now()),
+	}
+}
+
+// Resume continues a saved session instead of the new one
+func (u *ConversationUsecase) Resume(id string) error {
+	if len(u.messages) > 0 {
+		return fmt.Errorf("cannot resume a session after the conversation has started")
+	}
+
+	session, messages, err := u.sessions.Load(id)
+	if err != nil {
+		return fmt.Errorf("failed to resume session: %w", err)
+	}
+	u.session = session
+	u.messages = messages
+	return nil
+}
+
+// Session returns the session the conversation is saved as
+func (u *ConversationUsecase) Session() domain.Session {
+	return *u.session
+}
+
+// Messages returns the conversation so far
+func (u *ConversationUsecase) Messages() []anthropic.MessageParam {
+	return u.messages
+}
+
+// AddNote queues a note for the model, such as a changed resource, which is
+// sent along with the next user message
+func (u *ConversationUsecase) AddNote(note string) {
+	u.notesMu.Lock()
+	defer u.notesMu.Unlock()
+	u.notes = append(u.notes, note)
+}
+
+// takeNotes removes the queued notes and returns them as a user message, or false if there are none
+func (u *ConversationUsecase) takeNotes() (anthropic.MessageParam, bool) {
+	u.notesMu.Lock()
+	defer u.notesMu.Unlock()
+	if len(u.notes) == 0 {
+		return anthropic.MessageParam{}, false
+	}
+	blocks := make([]anthropic.ContentBlockParamUnion, len(u.notes))
+	for i, note := range u.notes {
+		blocks[i] = anthropic.NewTextBlock(note)
+	}
+	u.notes = nil
+	return anthropic.NewUserMessage(blocks...), true
+}
+
+// restoreNotes queues the notes of a message that could not be sent again
+func (u *ConversationUsecase) restoreNotes(message anthropic.MessageParam) {
+	u.notesMu.Lock()
+	defer u.notesMu.Unlock()
+	var notes []string
+	for _, content := range message.Content {
+		notes = append(notes, content.OfText.Text)
+	}
+	u.notes = append(notes, u.notes...)
+}
+
+// Send adds a user message and lets the model respond, running tools until it
+// stops asking for them. It returns the text of the model's final response.
+// If the model cannot be reached before any tool ran the conversation is left
+// as it was before the call. Tool calls that ran are kept, so they are not
+// made again when the message is retried.
+func (u *ConversationUsecase) Send(ctx context.Context, input SendInput) (string, error) {
+	if strings.TrimSpace(input.Text) == "" {
+		return "", fmt.Errorf("message cannot be empty")
+	}
+	return u.send(ctx, anthropic.NewUserMessage(anthropic.NewTextBlock(input.Text)))
+}
+
+// SendPrompt adds the messages of a prompt, such as an MCP server prompt, to
+// the conversation. When the prompt ends with a user message the model
+// responds right away, as with Send; otherwise the prompt only sets the scene
+// for the next message and the returned text is empty.
+func (u *ConversationUsecase) SendPrompt(ctx context.Context, messages []anthropic.MessageParam) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("prompt has no messages")
+	}
+	return u.send(ctx, messages...)
+}
+
+func (u *ConversationUsecase) send(ctx context.Context, messages ...anthropic.MessageParam) (string, error) {
+	// Messages are only ever appended or replaced, so this is the conversation to roll back to
+	previous := u.messages[:len(u.messages):len(u.messages)]
+	compaction := u.session.Compaction
+	turn := len(u.messages)
+	notes, hasNotes := u.takeNotes()
+	if hasNotes && messages[len(messages)-1].Role == anthropic.MessageParamRoleUser {
+		// The notes join the user message that follows them
+		messages = append([]anthropic.MessageParam{notes}, messages...)
+	} else if hasNotes {
+		// A prompt ending with the assistant leaves the notes for the next user message
+		u.restoreNotes(notes)
+		hasNotes = false
+	}
+	for _, message := range messages {
+		if len(u.messages) > 0 && u.messages[len(u.messages)-1].Role == message.Role {
+			// Roles must alternate, so the message joins the one before it
+			last := u.messages[len(u.messages)-1]
+			message.Content = append(append([]anthropic.ContentBlockParamUnion(nil), last.Content...), message.Content...)
+			u.messages = u.messages[: len(u.messages)-1 : len(u.messages)-1]
+			turn = min(turn, len(u.messages))
+		}
+		u.messages = append(u.messages, message)
+		for _, content := range message.Content {
+			if content.OfText != nil {
+				u.session.SetTitleFrom(content.OfText.Text)
+			}
+		}
+	}
+	u.save()
+	if u.messages[len(u.messages)-1].Role != anthropic.MessageParamRoleUser {
+		return "", nil
+	}
+
+	toolsRan := false
+	for {
+		// Servers may have changed their tools since the last request, e.g. after a login tool ran
+		u.tools.Refresh()
+		u.compact(ctx, turn)
+		response, err := u.createMessage(ctx)
+		if err != nil && toolsRan {
+			u.save()
+			return "", fmt.Errorf("failed to send message: %w", err)
+		}
+		if err != nil {
+			u.messages = previous
+			u.session.Compaction = compaction
+			u.save()
+			if hasNotes {
+				u.restoreNotes(notes)
+			}
+			return "", fmt.Errorf("failed to send message: %w", err)
+		}
+		u.auditModelTurn(response)
+
+		responseMessage := anthropic.MessageParam{
+			Role:    anthropic.MessageParamRoleAssistant,
+			Content: []anthropic.ContentBlockParamUnion{},
+		}
+		toolResults := anthropic.MessageParam{
+			Role:    anthropic.MessageParamRoleUser,
+			Content: []anthropic.ContentBlockParamUnion{},
+		}
+		var text strings.Builder
+		var toolUses []anthropic.ContentBlockUnion
+
+		for _, content := range response.Content {
+			switch content.Type {
+			case "text":
+				u.observer.OnText(content.Text)
+				text.WriteString(content.Text)
+				responseMessage.Content = append(responseMessage.Content, anthropic.NewTextBlock(content.Text))
+			case "tool_use":
+				responseMessage.Content = append(responseMessage.Content, anthropic.ContentBlockParamUnion{
+					OfToolUse: &anthropic.ToolUseBlockParam{
+						ID:    content.ID,
+						Name:  content.Name,
+						Input: content.Input,
+					},
+				})
+				toolUses = append(toolUses, content)
+			}
+		}
+
+		for i, toolResult := range u.callTools(ctx, response, toolUses) {
+			toolResult.ToolUseID = toolUses[i].ID
+			toolResults.Content = append(toolResults.Content, anthropic.ContentBlockParamUnion{
+				OfToolResult: &toolResult,
+			})
+		}
+
+		u.messages = append(u.messages, responseMessage)
+
+		// If we had tool_use, send the results to Claude before handing back to the user.
+		if len(toolResults.Content) == 0 {
+			u.save()
+			return text.String(), nil
+		}
+		u.messages = append(u.messages, toolResults)
+		toolsRan = true
+		u.save()
+	}
+}
+
+// save persists the conversation. A failure must not end the conversation, so it is only logged.
+func (u *ConversationUsecase) save() {
+	u.session.UpdatedAt = time.Now()
+	if err := u.sessions.Save(*u.session, u.messages); err != nil {
+		log.Printf("Failed to save session %s: %v", u.session.ID, err)
+	}
+}
+
+func (u *ConversationUsecase) createMessage(ctx context.Context) (*ports.ModelResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ModelTimeout)
+	defer cancel()
+
+	return u.model.CreateMessage(ctx, u.messageParams(), u.observer.OnTextDelta)
+}
+
+// messageParams builds the request for the next model response
+func (u *ConversationUsecase) messageParams() anthropic.MessageNewParams {
+	messageParams := anthropic.MessageNewParams{
+		Model:     anthropic.Model(u.settings.Model),
+		MaxTokens: u.settings.MaxTokens,
+		Messages:  u.history(),
+	}
+	if u.settings.SystemPrompt != "" {
+		messageParams.System = []anthropic.TextBlockParam{
+			{
+				Text: u.settings.SystemPrompt,
+			},
+		}
+	}
+	// The API rejects a tool choice without tools
+	if tools := u.tools.ToolParams(); len(tools) > 0 {
+		messageParams.Tools = tools
+		messageParams.ToolChoice = anthropic.ToolChoiceUnionParam{
+			OfAuto: &anthropic.ToolChoiceAutoParam{
+				// Independent tool calls may come in one response and run concurrently.
+				DisableParallelToolUse: anthropic.Bool(false),
+			},
+		}
+	}
+	return messageParams
+}
+
+// callTools runs the tool calls of one response concurrently and returns their
+// results in the same order. Calls needing approval are asked about one after
+// the other before they start. An exclusive tool waits for the calls before
+// it and runs alone, so calls are never reordered around it.
+func (u *ConversationUsecase) callTools(ctx context.Context, response *ports.ModelResponse, toolUses []anthropic.ContentBlockUnion) []anthropic.ToolResultBlockParam {
+	results := make([]anthropic.ToolResultBlockParam, len(toolUses))
+	var wg sync.WaitGroup
+	for i, toolUse := range toolUses {
+		u.observer.OnToolUse(toolUse.Name, toolUse.Input)
+
+		clearance := u.approve(ctx, toolUse.Name, toolUse.Input)
+		// The entry logs the input the tool is called with, and starts once
+		// the call is cleared so its duration leaves out the user's decision
+		arguments := clearance.input
+		if arguments == nil {
+			arguments = toolUse.Input
+		}
+		entry := domain.NewToolCallEntry(u.session.ID, u.tools.ToolServer(toolUse.Name), toolUse.Name, arguments, time.Now())
+		entry.Operator = u.settings.Operator
+		entry.ResponseID, entry.RequestID, entry.ToolUseID = response.ID, response.RequestID, toolUse.ID
+		entry.Cleared, entry.ApprovedBy = clearance.how, clearance.by
+		if clearance.result != nil {
+			results[i] = *clearance.result
+			u.auditToolCall(entry, results[i])
+			continue
+		}
+
+		if u.settings.Tool(toolUse.Name).Exclusive {
+			wg.Wait()
+			results[i] = u.callApprovedTool(ctx, toolUse.Name, toolUse.Input, clearance.input)
+			u.auditToolCall(entry, results[i])
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = u.callApprovedTool(ctx, toolUse.Name, toolUse.Input, clearance.input)
+			u.auditToolCall(entry, results[i])
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// clearance is the outcome of applying the approval policy of a tool to a call
+type clearance struct {
+	// input is what to call the tool with
+	input []byte
+	// how and by tell how the call was cleared, see domain.AuditEntry
+	how string
+	by  string
+	// result is given to the model instead of calling the tool when set
+	result *anthropic.ToolResultBlockParam
+}
+
+// approve applies the approval policy of the tool to a call. Calls that are
+// not made get a result telling the model why, and in a dry run what a
+// mutating tool would have been asked to do.
+func (u *ConversationUsecase) approve(ctx context.Context, name string, input []byte) clearance {
+	tool, hints := u.settings.Tool(name), u.tools.ToolHints(name)
+	if u.settings.DryRun && tool.Mutates(hints) {
+		// Nothing happens, so there is nothing to approve
+		return clearance{how: domain.ClearedDryRun, result: &anthropic.ToolResultBlockParam{
+			Content: []anthropic.ToolResultBlockParamContentUnion{{OfText: &anthropic.TextBlockParam{
+				Text: fmt.Sprintf("Dry run: %s changes data, so it was not called. It would have been called with %s. "+
+					"Continue as if the call succeeded.", name, input),
+			}}},
+		}}
+	}
+
+	denied := func(text string) clearance {
+		return clearance{how: domain.ClearedDenied, result: errorResult(text)}
+	}
+	switch tool.Policy(input, hints) {
+	case domain.ApprovalAllow:
+		return clearance{input: input, how: domain.ClearedByPolicy}
+	case domain.ApprovalAsk:
+	default:
+		return denied(fmt.Sprintf("The call was not made: the approval policy of %s denies it.", name))
+	}
+
+	if u.approver == nil {
+		return denied(fmt.Sprintf("The call was not made: %s needs the approval of the user, who cannot be asked here.", name))
+	}
+	decision, err := u.approver.ApproveToolCall(ctx, name, input)
+	if err != nil {
+		return denied(fmt.Sprintf("The call was not made: it needs the approval of the user, which failed: %v", err))
+	}
+	if !decision.Approved {
+		result := denied("The user denied the call.")
+		if decision.Reason != "" {
+			result = denied("The user denied the call: " + decision.Reason)
+		}
+		result.by = decision.User
+		return result
+	}
+	if decision.Input != nil {
+		input = decision.Input
+	}
+	return clearance{input: input, how: domain.ClearedByUser, by: decision.User}
+}
+
+// callApprovedTool calls a tool with the approved input and tells the model
+// when the user edited the input it asked for
+func (u *ConversationUsecase) callApprovedTool(ctx context.Context, name string, requested, input []byte) anthropic.ToolResultBlockParam {
+	result := u.callTool(ctx, name, input)
+	if !bytes.Equal(requested, input) {
+		note := anthropic.ToolResultBlockParamContentUnion{OfText: &anthropic.TextBlockParam{
+			Text: "The user changed the input of the call to: " + string(input),
+		}}
+		result.Content = append([]anthropic.ToolResultBlockParamContentUnion{note}, result.Content...)
+	}
+	return result
+}
+
+// errorResult reports a call that was not made to the model
+func errorResult(text string) *anthropic.ToolResultBlockParam {
+	return &anthropic.ToolResultBlockParam{
+		Content: []anthropic.ToolResultBlockParamContentUnion{{OfText: &anthropic.TextBlockParam{Text: text}}},
+		IsError: anthropic.Bool(true),
+	}
+}
+
+func (u *ConversationUsecase) callTool(ctx context.Context, name string, input []byte) anthropic.ToolResultBlockParam {
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ToolTimeout)
+	defer cancel()
+
+	return u.tools.CallTool(ctx, name, input)
+}
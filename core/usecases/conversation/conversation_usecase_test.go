@@ -0,0 +1,711 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp_client/core/domain"
+	"mcp_client/core/ports"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Mock implementation of LanguageModelPort replaying canned responses
+type mockModel struct {
+	responses []string
+	requests  []anthropic.MessageNewParams
+	// err is returned once the responses run out
+	err error
+}
+
+func (m *mockModel) CreateMessage(ctx context.Context, params anthropic.MessageNewParams, onTextDelta func(text string)) (*ports.ModelResponse, error) {
+	m.requests = append(m.requests, params)
+	if m.err != nil && len(m.responses) == 0 {
+		return nil, m.err
+	}
+
+	var message anthropic.Message
+	if err := json.Unmarshal([]byte(m.responses[0]), &message); err != nil {
+		return nil, err
+	}
+	m.responses = m.responses[1:]
+	for _, content := range message.Content {
+		if content.Type == "text" {
+			onTextDelta(content.Text)
+		}
+	}
+	return &ports.ModelResponse{Message: &message, RequestID: fmt.Sprintf("req_%d", len(m.requests))}, nil
+}
+
+// CountTokens counts four characters of the request as a token
+func (m *mockModel) CountTokens(ctx context.Context, params anthropic.MessageNewParams) (int64, error) {
+	data, err := json.Marshal(params)
+	return int64(len(data) / 4), err
+}
+
+// Mock implementation of ToolCatalogPort recording the calls it receives
+type mockTools struct {
+	mu        sync.Mutex
+	calls     []string
+	active    map[string]bool
+	overlaps  map[string][]string
+	callDelay time.Duration
+	hints     domain.ToolHints
+	refreshes int
+}
+
+func (m *mockTools) Refresh() {
+	m.refreshes++
+}
+
+func (m *mockTools) ToolParams() []anthropic.ToolUnionParam {
+	return []anthropic.ToolUnionParam{{OfTool: &anthropic.ToolParam{Name: "crm__lookup"}}}
+}
+
+func (m *mockTools) CallTool(ctx context.Context, name string, input []byte) anthropic.ToolResultBlockParam {
+	m.mu.Lock()
+	m.calls = append(m.calls, name)
+	if m.active == nil {
+		m.active = make(map[string]bool)
+		m.overlaps = make(map[string][]string)
+	}
+	for other := range m.active {
+		m.overlaps[name] = append(m.overlaps[name], other)
+		m.overlaps[other] = append(m.overlaps[other], name)
+	}
+	m.active[name] = true
+	m.mu.Unlock()
+
+	time.Sleep(m.callDelay)
+
+	m.mu.Lock()
+	delete(m.active, name)
+	m.mu.Unlock()
+	return anthropic.ToolResultBlockParam{
+		Content: []anthropic.ToolResultBlockParamContentUnion{
+			{OfText: &anthropic.TextBlockParam{Text: "result of " + string(input)}},
+		},
+	}
+}
+
+func (m *mockTools) ToolHints(name string) domain.ToolHints {
+	return m.hints
+}
+
+func (m *mockTools) ToolServer(name string) string {
+	return "crm"
+}
+
+// Mock implementation of ToolApproverPort giving the same answer to every call
+type mockApprover struct {
+	decision domain.ToolCallDecision
+	asked    []string
+	answered time.Time
+}
+
+func (m *mockApprover) ApproveToolCall(ctx context.Context, name string, input []byte) (domain.ToolCallDecision, error) {
+	m.asked = append(m.asked, name)
+	m.answered = time.Now()
+	return m.decision, nil
+}
+
+// Mock implementation of AuditLogPort keeping the entries in memory
+type mockAuditLog struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry
+}
+
+func (m *mockAuditLog) Record(entry domain.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Mock implementation of ConversationObserverPort
+type mockObserver struct {
+	deltas []string
+	texts  []string
+}
+
+func (m *mockObserver) OnTextDelta(text string)             { m.deltas = append(m.deltas, text) }
+func (m *mockObserver) OnText(text string)                  { m.texts = append(m.texts, text) }
+func (m *mockObserver) OnToolUse(name string, input []byte) {}
+
+// Mock implementation of SessionStorePort keeping sessions in memory
+type mockSessionStore struct {
+	sessions map[string]domain.Session
+	messages map[string][]anthropic.MessageParam
+	saves    int
+}
+
+func newMockSessionStore() *mockSessionStore {
+	return &mockSessionStore{
+		sessions: make(map[string]domain.Session),
+		messages: make(map[string][]anthropic.MessageParam),
+	}
+}
+
+func (m *mockSessionStore) Save(session domain.Session, messages []anthropic.MessageParam) error {
+	m.saves++
+	m.sessions[session.ID] = session
+	m.messages[session.ID] = append([]anthropic.MessageParam(nil), messages...)
+	return nil
+}
+
+func (m *mockSessionStore) Load(id string) (*domain.Session, []anthropic.MessageParam, error) {
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("session %s not found", id)
+	}
+	return &session, m.messages[id], nil
+}
+
+func (m *mockSessionStore) List() ([]domain.Session, error) {
+	return nil, nil
+}
+
+var testSettings = domain.ChatSettings{
+	Model:        "claude-test",
+	MaxTokens:    1024,
+	ModelTimeout: time.Second,
+	ToolTimeout:  time.Second,
+}
+
+const (
+	toolUseResponse = `{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"crm__lookup","input":{"id":"42"}}]}`
+	textResponse    = `{"role":"assistant","content":[{"type":"text","text":"The customer is Ada."}]}`
+)
+
+func TestConversationUsecase_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            SendInput
+		model            *mockModel
+		expectError      bool
+		expectReply      string
+		expectToolCalls  int
+		expectMessages   int
+		expectModelCalls int
+	}{
+		{
+			name:             "text response",
+			input:            SendInput{Text: "Hello"},
+			model:            &mockModel{responses: []string{textResponse}},
+			expectReply:      "The customer is Ada.",
+			expectMessages:   2,
+			expectModelCalls: 1,
+		},
+		{
+			name:             "tool use before answering",
+			input:            SendInput{Text: "Who is customer 42?"},
+			model:            &mockModel{responses: []string{toolUseResponse, textResponse}},
+			expectReply:      "The customer is Ada.",
+			expectToolCalls:  1,
+			expectMessages:   4,
+			expectModelCalls: 2,
+		},
+		{
+			name:        "empty message",
+			input:       SendInput{Text: "  "},
+			model:       &mockModel{},
+			expectError: true,
+		},
+		{
+			name:             "model error leaves the conversation untouched",
+			input:            SendInput{Text: "Hello"},
+			model:            &mockModel{err: errors.New("mock error")},
+			expectError:      true,
+			expectModelCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools := &mockTools{}
+			usecase := NewConversationUsecase(tt.model, tools, &mockApprover{}, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, testSettings)
+
+			reply, err := usecase.Send(context.Background(), tt.input)
+
+			if tt.expectError && err == nil {
+				t.Errorf("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("expected no error but got: %v", err)
+			}
+			if reply != tt.expectReply {
+				t.Errorf("expected reply %q but got %q", tt.expectReply, reply)
+			}
+			if len(tools.calls) != tt.expectToolCalls {
+				t.Errorf("expected %d tool calls but got %d", tt.expectToolCalls, len(tools.calls))
+			}
+			if len(usecase.Messages()) != tt.expectMessages {
+				t.Errorf("expected %d messages but got %d", tt.expectMessages, len(usecase.Messages()))
+			}
+			if len(tt.model.requests) != tt.expectModelCalls {
+				t.Errorf("expected %d model calls but got %d", tt.expectModelCalls, len(tt.model.requests))
+			}
+			if tools.refreshes != tt.expectModelCalls {
+				t.Errorf("expected the tools to be refreshed once per model call but got %d refreshes", tools.refreshes)
+			}
+		})
+	}
+}
+
+func TestConversationUsecase_ToolResultsAnswerToolUse(t *testing.T) {
+	model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+	usecase := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, testSettings)
+
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Who is customer 42?"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	toolResult := usecase.Messages()[2].Content[0].OfToolResult
+	if toolResult == nil || toolResult.ToolUseID != "toolu_1" {
+		t.Errorf("expected a tool result for toolu_1 but got: %+v", usecase.Messages()[2].Content[0])
+	}
+}
+
+func TestConversationUsecase_ParallelToolUse(t *testing.T) {
+	parallelResponse := `{"role":"assistant","content":[
+		{"type":"tool_use","id":"toolu_1","name":"crm__lookup","input":{"id":"1"}},
+		{"type":"tool_use","id":"toolu_2","name":"crm__lookup_slow","input":{"id":"2"}},
+		{"type":"tool_use","id":"toolu_3","name":"crm__update","input":{"id":"3"}}
+	]}`
+	model := &mockModel{responses: []string{parallelResponse, textResponse}}
+	tools := &mockTools{callDelay: 20 * time.Millisecond}
+	settings := testSettings
+	settings.Tools = map[string]domain.ToolConfig{"crm__update": {Exclusive: true}}
+	usecase := NewConversationUsecase(model, tools, &mockApprover{}, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, settings)
+
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Update customers 1 to 3"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	if len(tools.overlaps["crm__lookup"]) == 0 {
+		t.Errorf("expected the lookups to run concurrently")
+	}
+	if len(tools.overlaps["crm__update"]) != 0 {
+		t.Errorf("expected the exclusive tool to run alone but it overlapped with %v", tools.overlaps["crm__update"])
+	}
+
+	toolResults := usecase.Messages()[2].Content
+	for i, id := range []string{"toolu_1", "toolu_2", "toolu_3"} {
+		result := toolResults[i].OfToolResult
+		expected := fmt.Sprintf(`result of {"id":"%d"}`, i+1)
+		if result.ToolUseID != id || result.Content[0].OfText.Text != expected {
+			t.Errorf("expected result %d to answer %s with %q but got %s %q", i, id, expected, result.ToolUseID, result.Content[0].OfText.Text)
+		}
+	}
+}
+
+func TestConversationUsecase_SavesAndResumesSessions(t *testing.T) {
+	store := newMockSessionStore()
+	model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+	usecase := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, testSettings)
+
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Who is customer 42?"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	id := usecase.Session().ID
+	if len(store.messages[id]) != 4 {
+		t.Fatalf("expected the tool calls and results to be saved but got %d messages", len(store.messages[id]))
+	}
+	if title := store.sessions[id].Title; title != "Who is customer 42?" {
+		t.Errorf("expected the session to be titled after the first message but got %q", title)
+	}
+
+	model = &mockModel{responses: []string{textResponse}}
+	resumed := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, testSettings)
+	if err := resumed.Resume(id); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := resumed.Send(context.Background(), SendInput{Text: "And customer 43?"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	if len(model.requests[0].Messages) != 5 {
+		t.Errorf("expected the model to see the resumed conversation but got %d messages", len(model.requests[0].Messages))
+	}
+	if resumed.Session().ID != id || len(store.messages[id]) != 6 {
+		t.Errorf("expected the resumed conversation to be saved to %s with 6 messages", id)
+	}
+
+	if err := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, testSettings).Resume("missing"); err == nil {
+		t.Errorf("expected an error resuming an unknown session")
+	}
+}
+
+func TestConversationUsecase_KeepsToolCallsOfAFailedTurn(t *testing.T) {
+	store := newMockSessionStore()
+	model := &mockModel{responses: []string{toolUseResponse}, err: errors.New("request timed out")}
+	tools := &mockTools{}
+	usecase := NewConversationUsecase(model, tools, &mockApprover{}, &mockObserver{}, store, &mockAuditLog{}, testSettings)
+
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Register Ada"}); err == nil {
+		t.Fatalf("expected an error but got none")
+	}
+
+	if len(tools.calls) != 1 {
+		t.Fatalf("expected the tool to be called once but got %v", tools.calls)
+	}
+	// The user message, the tool_use and the tool_result
+	if messages := usecase.Messages(); len(messages) != 3 || messages[2].Content[0].OfToolResult == nil {
+		t.Errorf("expected the executed call to be kept but got %d messages", len(messages))
+	}
+	if saved := store.messages[usecase.Session().ID]; len(saved) != 3 {
+		t.Errorf("expected the executed call to be saved but got %d messages", len(saved))
+	}
+
+	// A retry goes on from the call instead of making it again
+	model.responses, model.err = []string{textResponse}, nil
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Did it work?"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(tools.calls) != 1 || len(usecase.Messages()) != 4 {
+		t.Errorf("expected the retry to follow the kept call but got %d calls and %d messages", len(tools.calls), len(usecase.Messages()))
+	}
+}
+
+func TestConversationUsecase_SendPrompt(t *testing.T) {
+	tests := []struct {
+		name             string
+		prompt           []anthropic.MessageParam
+		expectModelCalls int
+		expectMessages   int
+	}{
+		{
+			name: "prompt ending with a user message is answered",
+			prompt: []anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock("Onboard a new customer")),
+				anthropic.NewAssistantMessage(anthropic.NewTextBlock("What is their name?")),
+				anthropic.NewUserMessage(anthropic.NewTextBlock("Ada")),
+			},
+			expectModelCalls: 1,
+			expectMessages:   4,
+		},
+		{
+			name: "prompt ending with an assistant message waits for the user",
+			prompt: []anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock("Onboard a new customer")),
+				anthropic.NewAssistantMessage(anthropic.NewTextBlock("What is their name?")),
+			},
+			expectMessages: 2,
+		},
+		{
+			name: "consecutive user messages are joined",
+			prompt: []anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock("You onboard customers.")),
+				anthropic.NewUserMessage(anthropic.NewTextBlock("Onboard Ada")),
+			},
+			expectModelCalls: 1,
+			expectMessages:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &mockModel{responses: []string{textResponse}}
+			usecase := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, testSettings)
+
+			if _, err := usecase.SendPrompt(context.Background(), tt.prompt); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			if len(model.requests) != tt.expectModelCalls {
+				t.Errorf("expected %d model calls but got %d", tt.expectModelCalls, len(model.requests))
+			}
+			messages := usecase.Messages()
+			if len(messages) != tt.expectMessages {
+				t.Fatalf("expected %d messages but got %d", tt.expectMessages, len(messages))
+			}
+			for i := 1; i < len(messages); i++ {
+				if messages[i].Role == messages[i-1].Role {
+					t.Errorf("expected alternating roles but messages %d and %d are both %s", i-1, i, messages[i].Role)
+				}
+			}
+			if usecase.Session().Title != "Onboard a new customer" && usecase.Session().Title != "You onboard customers." {
+				t.Errorf("expected the session to be titled after the prompt but got %q", usecase.Session().Title)
+			}
+		})
+	}
+}
+
+func TestConversationUsecase_AddNote(t *testing.T) {
+	model := &mockModel{err: errors.New("mock error")}
+	usecase := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, testSettings)
+	usecase.AddNote("The resource tickets://open changed")
+
+	// A failed send keeps the note for the next attempt
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Any news?"}); err == nil {
+		t.Fatalf("expected error but got none")
+	}
+	model.err = nil
+	model.responses = []string{textResponse}
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Any news?"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	messages := usecase.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages but got %d", len(messages))
+	}
+	content := messages[0].Content
+	if len(content) != 2 || content[0].OfText.Text != "The resource tickets://open changed" || content[1].OfText.Text != "Any news?" {
+		t.Errorf("expected the note before the user message but got %+v", content)
+	}
+
+	model.responses = []string{textResponse}
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Thanks"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if content := usecase.Messages()[2].Content; len(content) != 1 {
+		t.Errorf("expected the note to be sent once but got %+v", content)
+	}
+}
+
+func TestConversationUsecase_ApprovesToolCalls(t *testing.T) {
+	destructive := domain.ToolHints{Destructive: true}
+	tests := []struct {
+		name         string
+		tool         domain.ToolConfig
+		hints        domain.ToolHints
+		decision     domain.ToolCallDecision
+		expectAsked  bool
+		expectCalled bool
+		expectResult string
+	}{
+		{
+			name:         "read-only tools run without asking",
+			hints:        domain.ToolHints{ReadOnly: true},
+			expectCalled: true,
+			expectResult: `result of {"id":"42"}`,
+		},
+		{
+			name:         "destructive tools run once approved",
+			hints:        destructive,
+			decision:     domain.ToolCallDecision{Approved: true},
+			expectAsked:  true,
+			expectCalled: true,
+			expectResult: `result of {"id":"42"}`,
+		},
+		{
+			name:         "denied calls report the reason",
+			hints:        destructive,
+			decision:     domain.ToolCallDecision{Reason: "wrong customer"},
+			expectAsked:  true,
+			expectResult: "The user denied the call: wrong customer",
+		},
+		{
+			name:         "edited input is used and reported",
+			hints:        destructive,
+			decision:     domain.ToolCallDecision{Approved: true, Input: []byte(`{"id":"43"}`)},
+			expectAsked:  true,
+			expectCalled: true,
+			expectResult: `The user changed the input of the call to: {"id":"43"}result of {"id":"43"}`,
+		},
+		{
+			name:         "configured policy overrides the hints",
+			tool:         domain.ToolConfig{Approval: domain.ApprovalAllow},
+			hints:        destructive,
+			expectCalled: true,
+			expectResult: `result of {"id":"42"}`,
+		},
+		{
+			name:         "denied tools are never called",
+			tool:         domain.ToolConfig{Approval: domain.ApprovalDeny},
+			expectResult: "The call was not made: the approval policy of crm__lookup denies it.",
+		},
+		{
+			name:         "matching input is asked about",
+			tool:         domain.ToolConfig{AskIf: `"id":"4\d"`},
+			decision:     domain.ToolCallDecision{Approved: true},
+			expectAsked:  true,
+			expectCalled: true,
+			expectResult: `result of {"id":"42"}`,
+		},
+		{
+			name:         "other input runs without asking",
+			tool:         domain.ToolConfig{AskIf: `"id":"9\d"`},
+			hints:        destructive,
+			expectCalled: true,
+			expectResult: `result of {"id":"42"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := testSettings
+			settings.Tools = map[string]domain.ToolConfig{"crm__lookup": tt.tool}
+			model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+			tools := &mockTools{hints: tt.hints}
+			approver := &mockApprover{decision: tt.decision}
+			usecase := NewConversationUsecase(model, tools, approver, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, settings)
+
+			if _, err := usecase.Send(context.Background(), SendInput{Text: "Delete customer 42"}); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			if asked := len(approver.asked) > 0; asked != tt.expectAsked {
+				t.Errorf("expected asked %v but got %v", tt.expectAsked, asked)
+			}
+			if called := len(tools.calls) > 0; called != tt.expectCalled {
+				t.Errorf("expected called %v but got %v", tt.expectCalled, called)
+			}
+			result := usecase.Messages()[2].Content[0].OfToolResult
+			var text string
+			for _, content := range result.Content {
+				text += content.OfText.Text
+			}
+			if text != tt.expectResult {
+				t.Errorf("expected result %q but got %q", tt.expectResult, text)
+			}
+			if result.IsError.Value == tt.expectCalled {
+				t.Errorf("expected the result to be an error only when the call was not made")
+			}
+		})
+	}
+}
+
+func TestConversationUsecase_DryRun(t *testing.T) {
+	mutating, notMutating := true, false
+	tests := []struct {
+		name         string
+		tool         domain.ToolConfig
+		hints        domain.ToolHints
+		expectCalled bool
+	}{
+		{name: "tools changing data are simulated", hints: domain.ToolHints{Destructive: true}},
+		{name: "read-only tools run", hints: domain.ToolHints{ReadOnly: true}, expectCalled: true},
+		{name: "configured as mutating", tool: domain.ToolConfig{Mutating: &mutating}, hints: domain.ToolHints{ReadOnly: true}},
+		{name: "configured as not mutating", tool: domain.ToolConfig{Mutating: &notMutating, Approval: domain.ApprovalAllow}, expectCalled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := testSettings
+			settings.DryRun = true
+			settings.Tools = map[string]domain.ToolConfig{"crm__lookup": tt.tool}
+			model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+			tools := &mockTools{hints: tt.hints}
+			approver := &mockApprover{}
+			usecase := NewConversationUsecase(model, tools, approver, &mockObserver{}, newMockSessionStore(), &mockAuditLog{}, settings)
+
+			if _, err := usecase.Send(context.Background(), SendInput{Text: "Delete customer 42"}); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			if called := len(tools.calls) > 0; called != tt.expectCalled {
+				t.Errorf("expected called %v but got %v", tt.expectCalled, called)
+			}
+			if len(approver.asked) > 0 {
+				t.Errorf("expected no approval to be asked for")
+			}
+			result := usecase.Messages()[2].Content[0].OfToolResult
+			if simulated := strings.HasPrefix(result.Content[0].OfText.Text, "Dry run:"); simulated == tt.expectCalled || result.IsError.Value {
+				t.Errorf("expected a simulated result only when the tool was not called but got %+v", result.Content[0].OfText)
+			}
+		})
+	}
+}
+
+func TestConversationUsecase_AuditsTurnsAndToolCalls(t *testing.T) {
+	model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+	tools := &mockTools{hints: domain.ToolHints{Destructive: true}}
+	approver := &mockApprover{decision: domain.ToolCallDecision{Approved: true, User: "ada"}}
+	auditLog := &mockAuditLog{}
+	settings := testSettings
+	settings.Operator = "ada"
+	usecase := NewConversationUsecase(model, tools, approver, &mockObserver{}, newMockSessionStore(), auditLog, settings)
+
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Delete customer 42"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	var kinds, requests []string
+	for _, entry := range auditLog.entries {
+		kinds = append(kinds, entry.Kind)
+		requests = append(requests, entry.RequestID)
+		if entry.Session != usecase.Session().ID || entry.Operator != "ada" {
+			t.Errorf("expected every entry to name the session and operator but got %q and %q", entry.Session, entry.Operator)
+		}
+	}
+	// The tool call carries the request whose response asked for it
+	if strings.Join(requests, ",") != "req_1,req_1,req_2" {
+		t.Errorf("expected the request IDs of the model turns but got %v", requests)
+	}
+	if usecase.Session().Operator != "ada" {
+		t.Errorf("expected the session to record its operator but got %q", usecase.Session().Operator)
+	}
+	expected := []string{domain.AuditModelTurn, domain.AuditToolCall, domain.AuditModelTurn}
+	if strings.Join(kinds, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected entries %v but got %v", expected, kinds)
+	}
+
+	call := auditLog.entries[1]
+	if call.Server != "crm" || call.Tool != "crm__lookup" || call.ToolUseID != "toolu_1" || string(call.Arguments) != `{"id":"42"}` {
+		t.Errorf("expected the call of crm__lookup but got %+v", call)
+	}
+	if call.Cleared != domain.ClearedByUser || call.ApprovedBy != "ada" {
+		t.Errorf("expected the call to be approved by ada but got %q by %q", call.Cleared, call.ApprovedBy)
+	}
+	if call.ResultSize == 0 || len(call.ResultSHA256) != 64 || call.Error != "" {
+		t.Errorf("expected the size and hash of a successful result but got %+v", call)
+	}
+}
+
+func TestConversationUsecase_AuditsTheApprovedInput(t *testing.T) {
+	model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+	tools := &mockTools{hints: domain.ToolHints{Destructive: true}}
+	approver := &mockApprover{decision: domain.ToolCallDecision{Approved: true, User: "ada", Input: []byte(`{"id":"43"}`)}}
+	auditLog := &mockAuditLog{}
+	usecase := NewConversationUsecase(model, tools, approver, &mockObserver{}, newMockSessionStore(), auditLog, testSettings)
+
+	if _, err := usecase.Send(context.Background(), SendInput{Text: "Delete customer 42"}); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	call := auditLog.entries[1]
+	if string(call.Arguments) != `{"id":"43"}` {
+		t.Errorf("expected the input the user approved but got %s", call.Arguments)
+	}
+	if call.Time.Before(approver.answered) {
+		t.Errorf("expected the call to start after the approval at %v but got %v", approver.answered, call.Time)
+	}
+}
+
+func TestConversationUsecase_AuditsTheOperatorOfCallsNobodyApproved(t *testing.T) {
+	tests := []struct {
+		name          string
+		dryRun        bool
+		expectCleared string
+	}{
+		{name: "allowed by policy", expectCleared: domain.ClearedByPolicy},
+		{name: "dry run", dryRun: true, expectCleared: domain.ClearedDryRun},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &mockModel{responses: []string{toolUseResponse, textResponse}}
+			auditLog := &mockAuditLog{}
+			settings := testSettings
+			settings.Operator, settings.DryRun = "ada", tt.dryRun
+			usecase := NewConversationUsecase(model, &mockTools{}, &mockApprover{}, &mockObserver{}, newMockSessionStore(), auditLog, settings)
+
+			if _, err := usecase.Send(context.Background(), SendInput{Text: "Update customer 42"}); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			call := auditLog.entries[1]
+			if call.Cleared != tt.expectCleared || call.ApprovedBy != "" || call.Operator != "ada" {
+				t.Errorf("expected a call cleared by %s on behalf of ada but got %+v", tt.expectCleared, call)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"mcp_client/core/domain"
+	"mcp_client/core/ports"
+	"sync"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Input structs for the usecase
+type CreateMessageInput struct {
+	// Server is the name of the server asking for the completion
+	Server string
+	// Params holds the messages, system prompt and sampling options of the
+	// request. The model is chosen by the usecase.
+	Params     anthropic.MessageNewParams
+	ModelHints []string
+}
+
+// SamplingUsecase runs model completions on behalf of servers. Every request
+// is approved by the user unless auto approval is configured, and each server
+// may use up to its token budget.
+type SamplingUsecase struct {
+	model    ports.LanguageModelPort
+	approver ports.SamplingApproverPort
+	settings domain.SamplingSettings
+
+	mu sync.Mutex
+	// used counts the tokens spent or reserved per server
+	used map[string]int64
+}
+
+// NewSamplingUsecase creates a new instance of the usecase
+func NewSamplingUsecase(
+	model ports.LanguageModelPort,
+	approver ports.SamplingApproverPort,
+	settings domain.SamplingSettings,
+) *SamplingUsecase {
+	return &SamplingUsecase{
+		model:    model,
+		approver: approver,
+		settings: settings,
+		used:     make(map[string]int64),
+	}
+}
+
+// CreateMessage sends the request of a server to the model and returns the response
+func (u *SamplingUsecase) CreateMessage(ctx context.Context, input CreateMessageInput) (*anthropic.Message, error) {
+	params := input.Params
+	if len(params.Messages) == 0 {
+		return nil, fmt.Errorf("sampling request has no messages")
+	}
+	if params.MaxTokens <= 0 {
+		return nil, fmt.Errorf("sampling request must allow a positive number of tokens, got %d", params.MaxTokens)
+	}
+	params.Model = anthropic.Model(u.settings.SelectModel(input.ModelHints))
+	params.MaxTokens = min(params.MaxTokens, u.settings.MaxTokens)
+
+	inputTokens, err := u.countTokens(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	reserved, remaining, err := u.reserve(input.Server, inputTokens, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.settings.AutoApprove {
+		approved, err := u.approver.ApproveSampling(ctx, input.Server, params, remaining)
+		if err != nil {
+			u.settle(input.Server, reserved, 0)
+			return nil, fmt.Errorf("failed to get approval: %w", err)
+		}
+		if !approved {
+			u.settle(input.Server, reserved, 0)
+			return nil, fmt.Errorf("the user declined the sampling request")
+		}
+	}
+
+	response, err := u.createMessage(ctx, params)
+	if err != nil {
+		u.settle(input.Server, reserved, 0)
+		return nil, fmt.Errorf("failed to sample: %w", err)
+	}
+	u.settle(input.Server, reserved, response.Usage.InputTokens+response.Usage.OutputTokens)
+	return response.Message, nil
+}
+
+// Used returns the number of tokens the server has used so far
+func (u *SamplingUsecase) Used(server string) int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.used[server]
+}
+
+func (u *SamplingUsecase) countTokens(ctx context.Context, params anthropic.MessageNewParams) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ModelTimeout)
+	defer cancel()
+
+	tokens, err := u.model.CountTokens(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to size sampling request: %w", err)
+	}
+	return tokens, nil
+}
+
+// reserve sets aside the tokens the request may use at most, shortening the
+// response to what is left of the budget. It returns the reserved tokens and
+// what remains of the budget before them.
+func (u *SamplingUsecase) reserve(server string, inputTokens int64, params *anthropic.MessageNewParams) (int64, int64, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	remaining := u.settings.TokenBudget - u.used[server]
+	if inputTokens >= remaining {
+		return 0, 0, fmt.Errorf("server %s has %d of its %d sampling tokens left, the request needs %d for its input alone",
+			server, max(remaining, 0), u.settings.TokenBudget, inputTokens)
+	}
+	params.MaxTokens = min(params.MaxTokens, remaining-inputTokens)
+	reserved := inputTokens + params.MaxTokens
+	u.used[server] += reserved
+	return reserved, remaining, nil
+}
+
+// settle replaces a reservation by the tokens actually used
+func (u *SamplingUsecase) settle(server string, reserved, used int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.used[server] += used - reserved
+}
+
+func (u *SamplingUsecase) createMessage(ctx context.Context, params anthropic.MessageNewParams) (*ports.ModelResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ModelTimeout)
+	defer cancel()
+
+	return u.model.CreateMessage(ctx, params, func(string) {})
+}
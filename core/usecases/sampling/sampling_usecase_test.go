@@ -0,0 +1,188 @@
+package sampling
+
+import (
+	"context"
+	"mcp_client/core/domain"
+	"mcp_client/core/ports"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Mock implementation of LanguageModelPort with a fixed input size and answer
+type mockModel struct {
+	inputTokens  int64
+	outputTokens int64
+	requests     []anthropic.MessageNewParams
+}
+
+func (m *mockModel) CreateMessage(ctx context.Context, params anthropic.MessageNewParams, onTextDelta func(text string)) (*ports.ModelResponse, error) {
+	m.requests = append(m.requests, params)
+	return &ports.ModelResponse{Message: &anthropic.Message{
+		Model:      params.Model,
+		Content:    []anthropic.ContentBlockUnion{{Type: "text", Text: "Yes, same person."}},
+		StopReason: anthropic.StopReasonEndTurn,
+		Usage:      anthropic.Usage{InputTokens: m.inputTokens, OutputTokens: m.outputTokens},
+	}}, nil
+}
+
+func (m *mockModel) CountTokens(ctx context.Context, params anthropic.MessageNewParams) (int64, error) {
+	return m.inputTokens, nil
+}
+
+// Mock implementation of SamplingApproverPort recording what it was asked
+type mockApprover struct {
+	approve   bool
+	asked     int
+	remaining int64
+}
+
+func (m *mockApprover) ApproveSampling(ctx context.Context, server string, params anthropic.MessageNewParams, remaining int64) (bool, error) {
+	m.asked++
+	m.remaining = remaining
+	return m.approve, nil
+}
+
+var testSettings = domain.SamplingSettings{
+	Model:        "claude-3-7-sonnet-latest",
+	Models:       []string{"claude-3-5-haiku-latest", "claude-3-7-sonnet-latest"},
+	MaxTokens:    500,
+	TokenBudget:  1000,
+	ModelTimeout: time.Second,
+}
+
+func TestSamplingUsecase_CreateMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		approve         bool
+		autoApprove     bool
+		usedBefore      int64
+		maxTokens       int64
+		hints           []string
+		expectError     bool
+		expectAsked     int
+		expectModel     string
+		expectMaxTokens int64
+		expectUsed      int64
+	}{
+		{
+			name:            "approved request uses the default model",
+			approve:         true,
+			maxTokens:       100,
+			expectAsked:     1,
+			expectModel:     "claude-3-7-sonnet-latest",
+			expectMaxTokens: 100,
+			expectUsed:      250,
+		},
+		{
+			name:            "model hints pick a configured model",
+			approve:         true,
+			maxTokens:       100,
+			hints:           []string{"gpt-4o", "haiku"},
+			expectAsked:     1,
+			expectModel:     "claude-3-5-haiku-latest",
+			expectMaxTokens: 100,
+			expectUsed:      250,
+		},
+		{
+			name:            "response length is capped by the settings",
+			approve:         true,
+			maxTokens:       4000,
+			expectAsked:     1,
+			expectModel:     "claude-3-7-sonnet-latest",
+			expectMaxTokens: 500,
+			expectUsed:      250,
+		},
+		{
+			name:            "response length is capped by the remaining budget",
+			approve:         true,
+			usedBefore:      700,
+			maxTokens:       400,
+			expectAsked:     1,
+			expectModel:     "claude-3-7-sonnet-latest",
+			expectMaxTokens: 100,
+			expectUsed:      950,
+		},
+		{
+			name:        "declined request does not reach the model",
+			maxTokens:   100,
+			expectError: true,
+			expectAsked: 1,
+		},
+		{
+			name:            "auto approval skips the user",
+			autoApprove:     true,
+			maxTokens:       100,
+			expectModel:     "claude-3-7-sonnet-latest",
+			expectMaxTokens: 100,
+			expectUsed:      250,
+		},
+		{
+			name:        "exhausted budget",
+			approve:     true,
+			usedBefore:  900,
+			maxTokens:   100,
+			expectError: true,
+		},
+		{
+			name:        "request without tokens",
+			approve:     true,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &mockModel{inputTokens: 200, outputTokens: 50}
+			approver := &mockApprover{approve: tt.approve}
+			settings := testSettings
+			settings.AutoApprove = tt.autoApprove
+			usecase := NewSamplingUsecase(model, approver, settings)
+			usecase.used["dedupe"] = tt.usedBefore
+
+			response, err := usecase.CreateMessage(context.Background(), CreateMessageInput{
+				Server: "dedupe",
+				Params: anthropic.MessageNewParams{
+					MaxTokens: tt.maxTokens,
+					Messages: []anthropic.MessageParam{
+						anthropic.NewUserMessage(anthropic.NewTextBlock("Are Ada Lovelace and A. Lovelace the same person?")),
+					},
+				},
+				ModelHints: tt.hints,
+			})
+
+			if approver.asked != tt.expectAsked {
+				t.Errorf("expected the user to be asked %d times but was asked %d times", tt.expectAsked, approver.asked)
+			}
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got none")
+				}
+				if len(model.requests) != 0 {
+					t.Errorf("expected no model request but got %d", len(model.requests))
+				}
+				if used := usecase.Used("dedupe"); used != tt.usedBefore {
+					t.Errorf("expected the budget to stay at %d used but got %d", tt.usedBefore, used)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if response.Content[0].Text != "Yes, same person." {
+				t.Errorf("unexpected response %+v", response)
+			}
+			request := model.requests[0]
+			if string(request.Model) != tt.expectModel {
+				t.Errorf("expected model %s but got %s", tt.expectModel, request.Model)
+			}
+			if request.MaxTokens != tt.expectMaxTokens {
+				t.Errorf("expected max tokens %d but got %d", tt.expectMaxTokens, request.MaxTokens)
+			}
+			if used := usecase.Used("dedupe"); used != tt.expectUsed {
+				t.Errorf("expected %d tokens used but got %d", tt.expectUsed, used)
+			}
+		})
+	}
+}